@@ -0,0 +1,2 @@
+# Databases are imported by <cluster>:<database>, leave the cluster empty for non clustered databases
+terraform import clickhouse_db.awesome_database :awesome_database
@@ -0,0 +1,4 @@
+# Dictionaries are imported by <cluster>:<database>:<dictionary>, leave the cluster empty for non clustered dictionaries.
+# The source and range aren't read back from Clickhouse, they are taken from the configuration on the next apply
+terraform import clickhouse_dictionary.clustered_dictionary "'{cluster}':awesome_database:clustered_dictionary"
+terraform import clickhouse_dictionary.users :awesome_database:users_dict
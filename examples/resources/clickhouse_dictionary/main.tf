@@ -0,0 +1,95 @@
+terraform {
+  required_providers {
+    clickhouse = {
+      version = "2.0.0"
+      source  = "hashicorp.com/ivanofthings/clickhouse"
+    }
+  }
+}
+
+provider "clickhouse" {
+  port = 8123
+}
+
+resource "clickhouse_db" "test_db" {
+  name    = "awesome_database"
+  comment = "This is an awesome database"
+}
+
+// Queried with dictGet('awesome_database.users_dict', 'name', toUInt64(42))
+resource "clickhouse_dictionary" "users" {
+  database    = clickhouse_db.test_db.name
+  name        = "users_dict"
+  comment     = "Users by id"
+  primary_key = ["id"]
+  attribute {
+    name = "id"
+    type = "UInt64"
+  }
+  attribute {
+    name    = "name"
+    type    = "String"
+    default = "''"
+  }
+  source {
+    type = "clickhouse"
+    parameters = {
+      db    = clickhouse_db.test_db.name
+      table = "users"
+      user  = "dictionaries"
+    }
+    password = var.dictionaries_password
+  }
+  layout {
+    type = "hashed"
+  }
+  lifetime {
+    min = 300
+    max = 600
+  }
+}
+
+// Prices valid between two dates, loaded from a remote file
+resource "clickhouse_dictionary" "prices" {
+  database    = clickhouse_db.test_db.name
+  name        = "prices_dict"
+  primary_key = ["product_id"]
+  attribute {
+    name = "product_id"
+    type = "UInt64"
+  }
+  attribute {
+    name = "start_date"
+    type = "Date"
+  }
+  attribute {
+    name = "end_date"
+    type = "Nullable(Date)"
+  }
+  attribute {
+    name = "price"
+    type = "Float64"
+  }
+  source {
+    type = "http"
+    parameters = {
+      url    = "https://example.com/prices.tsv"
+      format = "TabSeparated"
+    }
+  }
+  layout {
+    type = "range_hashed"
+  }
+  range {
+    min = "start_date"
+    max = "end_date"
+  }
+  lifetime {
+    max = 3600
+  }
+}
+
+variable "dictionaries_password" {
+  type      = string
+  sensitive = true
+}
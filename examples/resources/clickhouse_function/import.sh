@@ -0,0 +1,3 @@
+# Functions are imported by <cluster>:<function>, leave the cluster empty for non clustered functions
+terraform import clickhouse_function.clustered_function "'{cluster}':clustered_function"
+terraform import clickhouse_function.url_domain :url_domain
@@ -0,0 +1,25 @@
+terraform {
+  required_providers {
+    clickhouse = {
+      version = "2.0.0"
+      source  = "hashicorp.com/ivanofthings/clickhouse"
+    }
+  }
+}
+
+provider "clickhouse" {
+  port = 8123
+}
+
+// Called like any other function: SELECT url_domain(referer) FROM events
+resource "clickhouse_function" "url_domain" {
+  name       = "url_domain"
+  parameters = ["url"]
+  expression = "lower(domainWithoutWWW(url))"
+}
+
+resource "clickhouse_function" "linear_equation" {
+  name       = "linear_equation"
+  parameters = ["x", "k", "b"]
+  expression = "k * x + b"
+}
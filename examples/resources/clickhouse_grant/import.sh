@@ -0,0 +1,4 @@
+# Grants are imported by <grantee>:<database>:<table>:<columns>, with '*' for every database or table and
+# the columns separated by commas, leave the columns empty for table level grants
+terraform import clickhouse_grant.events_read analysts:awesome_database:events:
+terraform import clickhouse_grant.users_ids analysts:awesome_database:users:id,country
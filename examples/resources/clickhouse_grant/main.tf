@@ -0,0 +1,41 @@
+terraform {
+  required_providers {
+    clickhouse = {
+      version = "2.0.0"
+      source  = "hashicorp.com/ivanofthings/clickhouse"
+    }
+  }
+}
+
+provider "clickhouse" {
+  port = 8123
+}
+
+// The privileges of a role managed by clickhouse_grant must not be set on its clickhouse_role too,
+// the role resource would report them as drift
+resource "clickhouse_role" "analysts" {
+  name     = "analysts"
+  database = "awesome_database"
+}
+
+resource "clickhouse_grant" "events_read" {
+  grantee    = clickhouse_role.analysts.name
+  privileges = ["SELECT"]
+  database   = "awesome_database"
+  table      = "events"
+}
+
+// Only some columns of the users table can be read
+resource "clickhouse_grant" "users_ids" {
+  grantee    = clickhouse_role.analysts.name
+  privileges = ["SELECT"]
+  database   = "awesome_database"
+  table      = "users"
+  columns    = ["id", "country"]
+}
+
+resource "clickhouse_grant" "functions" {
+  grantee           = clickhouse_role.analysts.name
+  privileges        = ["CREATE FUNCTION", "DROP FUNCTION"]
+  with_grant_option = true
+}
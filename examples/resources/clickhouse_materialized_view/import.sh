@@ -0,0 +1,3 @@
+# Materialized views are imported by <cluster>:<database>:<view>, leave the cluster empty for non clustered views
+terraform import clickhouse_materialized_view.clustered_view "'{cluster}':awesome_database:clustered_view"
+terraform import clickhouse_materialized_view.daily_events_mv :awesome_database:daily_events_mv
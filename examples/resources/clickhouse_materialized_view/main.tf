@@ -0,0 +1,56 @@
+terraform {
+  required_providers {
+    clickhouse = {
+      version = "2.0.0"
+      source  = "hashicorp.com/ivanofthings/clickhouse"
+    }
+  }
+}
+
+provider "clickhouse" {
+  port = 8123
+}
+
+resource "clickhouse_db" "test_db" {
+  name    = "awesome_database"
+  comment = "This is an awesome database"
+}
+
+resource "clickhouse_table" "daily_events" {
+  database = clickhouse_db.test_db.name
+  name     = "daily_events"
+  engine   = "SummingMergeTree"
+  order_by = ["day"]
+  column {
+    name = "day"
+    type = "Date"
+  }
+  column {
+    name = "events"
+    type = "UInt64"
+  }
+}
+
+// Rows inserted into events are aggregated into the daily_events table
+resource "clickhouse_materialized_view" "daily_events_mv" {
+  database = clickhouse_db.test_db.name
+  name     = "daily_events_mv"
+  to_table = clickhouse_table.daily_events.name
+  comment  = "Events per day"
+  query    = <<-EOT
+    SELECT toDate(event_date) AS day, count() AS events
+    FROM events
+    GROUP BY day
+  EOT
+}
+
+// Rows are stored in an inner table, filled with the events already existing when the view is created
+resource "clickhouse_materialized_view" "monthly_events_mv" {
+  database     = clickhouse_db.test_db.name
+  name         = "monthly_events_mv"
+  engine       = "SummingMergeTree"
+  order_by     = ["month"]
+  partition_by = ["toYear(month)"]
+  populate     = true
+  query        = "SELECT toStartOfMonth(event_date) AS month, count() AS events FROM events GROUP BY month"
+}
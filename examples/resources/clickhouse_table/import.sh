@@ -0,0 +1,3 @@
+# Tables are imported by <cluster>:<database>:<table>, leave the cluster empty for non clustered tables
+terraform import clickhouse_table.replicated_table "'{cluster}':awesome_database:replicated_table"
+terraform import clickhouse_table.local_table :awesome_database:local_table
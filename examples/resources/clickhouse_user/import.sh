@@ -0,0 +1,2 @@
+# Users are imported by name. The password can't be read back, so it is set to the configured one on the next apply
+terraform import clickhouse_user.awesome_user awesome_user
@@ -0,0 +1,3 @@
+# Views are imported by <cluster>:<database>:<view>, leave the cluster empty for non clustered views
+terraform import clickhouse_view.clustered_view "'{cluster}':awesome_database:clustered_view"
+terraform import clickhouse_view.daily_events :awesome_database:daily_events
@@ -0,0 +1,35 @@
+terraform {
+  required_providers {
+    clickhouse = {
+      version = "2.0.0"
+      source  = "hashicorp.com/ivanofthings/clickhouse"
+    }
+  }
+}
+
+provider "clickhouse" {
+  port = 8123
+}
+
+resource "clickhouse_db" "test_db" {
+  name    = "awesome_database"
+  comment = "This is an awesome database"
+}
+
+resource "clickhouse_view" "daily_events" {
+  database = clickhouse_db.test_db.name
+  name     = "daily_events"
+  comment  = "Events per day"
+  query    = <<-EOT
+    SELECT toDate(event_date) AS day, count() AS events
+    FROM events
+    GROUP BY day
+  EOT
+}
+
+// Parameterized views take their parameters when queried: SELECT * FROM user_events(user_id = 42)
+resource "clickhouse_view" "user_events" {
+  database = clickhouse_db.test_db.name
+  name     = "user_events"
+  query    = "SELECT * FROM events WHERE user_id = {user_id:UInt64}"
+}
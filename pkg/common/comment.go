@@ -0,0 +1,100 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CommentProvider marks the comments holding metadata written by this provider.
+const CommentProvider = "terraform-provider-clickhouse"
+
+// CommentVersion is the version of the metadata envelope written by GetComment.
+// Version 0 is the legacy {"comment":"...","cluster":"..."} format without provider marker.
+const CommentVersion = 1
+
+// commentMetadata is the envelope stored as the Clickhouse comment of the objects created by the provider,
+// it keeps the user comment along with the information that can't be read back from system tables.
+type commentMetadata struct {
+	Provider string `json:"provider"`
+	Version  int    `json:"version"`
+	Comment  string `json:"comment"`
+	Cluster  string `json:"cluster"`
+}
+
+// GetComment encodes the user comment and the cluster into the metadata envelope.
+func GetComment(comment string, cluster string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	// Keep <, > and & readable when the comment is inspected in Clickhouse
+	encoder.SetEscapeHTML(false)
+	// Encoding a struct of strings and ints can't fail
+	_ = encoder.Encode(commentMetadata{
+		Provider: CommentProvider,
+		Version:  CommentVersion,
+		Comment:  comment,
+		Cluster:  cluster,
+	})
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// UnmarshalComment decodes a comment written by GetComment, legacy comments written by previous provider
+// versions are decoded as well so they are migrated to the current envelope on the next write.
+// Comments not written by the provider, like the ones of objects created by hand, return an error.
+func UnmarshalComment(storedComment string) (comment string, cluster string, err error) {
+	var metadata commentMetadata
+	if err := json.Unmarshal([]byte(storedComment), &metadata); err == nil && metadata.Provider == CommentProvider {
+		if metadata.Version > CommentVersion {
+			return "", "", fmt.Errorf("comment metadata version %d is newer than the supported %d", metadata.Version, CommentVersion)
+		}
+		return metadata.Comment, metadata.Cluster, nil
+	}
+
+	return unmarshalLegacyComment(storedComment)
+}
+
+// DecodeComment returns the user comment and the cluster stored in a comment by the provider. Objects created
+// outside the provider, e.g. imported ones, carry a plain comment without metadata, which is returned as it is.
+func DecodeComment(storedComment string) (comment string, cluster string) {
+	comment, cluster, err := UnmarshalComment(storedComment)
+	if err != nil {
+		return storedComment, ""
+	}
+	return comment, cluster
+}
+
+// KnownCluster returns the cluster read from an object comment or, when it isn't recorded there like on objects
+// created outside the provider, the one already known from the state.
+func KnownCluster(readCluster string, stateCluster string) string {
+	if readCluster == "" {
+		return stateCluster
+	}
+	return readCluster
+}
+
+// IsLegacyComment tells whether the stored comment was written in the version 0 format by a previous provider
+// version. Reads keep such comments as they are stored, so the next apply rewrites them in the current envelope.
+func IsLegacyComment(storedComment string) bool {
+	var metadata commentMetadata
+	if err := json.Unmarshal([]byte(storedComment), &metadata); err == nil && metadata.Provider == CommentProvider {
+		return false
+	}
+	_, _, err := unmarshalLegacyComment(storedComment)
+	return err == nil
+}
+
+// unmarshalLegacyComment decodes the version 0 format, built without escaping so quotes were stored as \'.
+func unmarshalLegacyComment(storedComment string) (comment string, cluster string, err error) {
+	var legacy map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.Replace(storedComment, "\\'", "'", -1)), &legacy); err != nil {
+		return "", "", fmt.Errorf("comment doesn't hold the provider metadata: %v", err)
+	}
+
+	comment, commentOk := legacy["comment"].(string)
+	cluster, clusterOk := legacy["cluster"].(string)
+	if len(legacy) != 2 || !commentOk || !clusterOk {
+		return "", "", fmt.Errorf("comment %q doesn't hold the provider metadata", storedComment)
+	}
+	return comment, cluster, nil
+}
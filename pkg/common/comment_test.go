@@ -0,0 +1,82 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommentRoundTrip(t *testing.T) {
+	tests := []struct {
+		comment string
+		cluster string
+	}{
+		{"plain comment", ""},
+		{`quotes "double" and 'single'`, "cluster"},
+		{`back\slash \n not a newline`, "'{cluster}'"},
+		{"unicode ñandú 数据 🚀 <tag> & more", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		stored := GetComment(test.comment, test.cluster)
+		if !strings.Contains(stored, `"provider":"`+CommentProvider+`"`) || !strings.Contains(stored, `"version":1`) {
+			t.Errorf("GetComment(%q) = %s, expected provider and version metadata", test.comment, stored)
+		}
+		comment, cluster, err := UnmarshalComment(stored)
+		if err != nil {
+			t.Errorf("UnmarshalComment(%s) failed: %v", stored, err)
+			continue
+		}
+		if comment != test.comment || cluster != test.cluster {
+			t.Errorf("UnmarshalComment(%s) = %q, %q, expected %q, %q", stored, comment, cluster, test.comment, test.cluster)
+		}
+	}
+}
+
+func TestUnmarshalLegacyComment(t *testing.T) {
+	comment, cluster, err := UnmarshalComment(`{"comment":"it\'s a comment","cluster":"cluster"}`)
+	if err != nil || comment != "it's a comment" || cluster != "cluster" {
+		t.Errorf("unexpected legacy comment decoding %q, %q, %v", comment, cluster, err)
+	}
+}
+
+func TestIsLegacyComment(t *testing.T) {
+	if !IsLegacyComment(`{"comment":"it\'s a comment","cluster":"cluster"}`) {
+		t.Errorf("version 0 comment expected to be legacy")
+	}
+	for _, storedComment := range []string{GetComment("a comment", "cluster"), "created by hand", ""} {
+		if IsLegacyComment(storedComment) {
+			t.Errorf("IsLegacyComment(%q) expected to be false", storedComment)
+		}
+	}
+}
+
+func TestUnmarshalForeignComment(t *testing.T) {
+	foreignComments := []string{
+		"created by hand",
+		`{"owner":"team"}`,
+		`{"comment":1,"cluster":""}`,
+		`{"comment":"a","cluster":"b","owner":"team"}`,
+		`{"provider":"other","version":1,"comment":"a","cluster":""}`,
+		"",
+	}
+
+	for _, foreignComment := range foreignComments {
+		if _, _, err := UnmarshalComment(foreignComment); err == nil {
+			t.Errorf("UnmarshalComment(%q) expected to fail on a foreign comment", foreignComment)
+		}
+	}
+
+	if _, _, err := UnmarshalComment(`{"provider":"` + CommentProvider + `","version":99,"comment":"a","cluster":""}`); err == nil {
+		t.Errorf("UnmarshalComment expected to fail on a newer metadata version")
+	}
+}
+
+func TestDecodeComment(t *testing.T) {
+	if comment, cluster := DecodeComment(GetComment("a comment", "cluster")); comment != "a comment" || cluster != "cluster" {
+		t.Errorf("unexpected comment decoding %q, %q", comment, cluster)
+	}
+	if comment, cluster := DecodeComment(`{"owner":"team"}`); comment != `{"owner":"team"}` || cluster != "" {
+		t.Errorf("plain comments are expected to be kept as they are, got %q, %q", comment, cluster)
+	}
+}
@@ -0,0 +1,259 @@
+package common
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	chdriver "github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// retryableExceptionCodes are the Clickhouse exception codes of transient failures, worth retrying.
+var retryableExceptionCodes = map[int32]string{
+	159: "TIMEOUT_EXCEEDED",
+	202: "TOO_MANY_SIMULTANEOUS_QUERIES",
+	203: "NO_FREE_CONNECTION",
+	209: "SOCKET_TIMEOUT",
+	210: "NETWORK_ERROR",
+	242: "TABLE_IS_READ_ONLY",
+	252: "TOO_MANY_PARTS",
+	285: "TOO_FEW_LIVE_REPLICAS",
+	319: "UNKNOWN_STATUS_OF_INSERT",
+	425: "SYSTEM_ERROR",
+	473: "DEADLOCK_AVOIDED",
+	999: "KEEPER_EXCEPTION",
+}
+
+// execRetryableExceptionCodes are the transient failures of statements refused before running, so they can be
+// retried even when they are not idempotent. A CREATE that timed out or lost its connection may have been applied,
+// and its retry would fail with an already exists error.
+var execRetryableExceptionCodes = map[int32]string{
+	202: "TOO_MANY_SIMULTANEOUS_QUERIES",
+	203: "NO_FREE_CONNECTION",
+	242: "TABLE_IS_READ_ONLY",
+	252: "TOO_MANY_PARTS",
+	285: "TOO_FEW_LIVE_REPLICAS",
+	473: "DEADLOCK_AVOIDED",
+}
+
+// httpExceptionCodeRegex extracts the exception code from the errors of the HTTP interface, which
+// are not decoded into proto.Exception, e.g. "Code: 202. DB::Exception: Too many simultaneous queries".
+var httpExceptionCodeRegex = regexp.MustCompile(`Code: (\d+)\. DB::Exception`)
+
+// exceptionCode returns the Clickhouse exception code of an error, if any.
+func exceptionCode(err error) (int32, bool) {
+	var exception *proto.Exception
+	if errors.As(err, &exception) {
+		return exception.Code, true
+	}
+	if matches := httpExceptionCodeRegex.FindStringSubmatch(err.Error()); matches != nil {
+		code, err := strconv.ParseInt(matches[1], 10, 32)
+		return int32(code), err == nil
+	}
+	return 0, false
+}
+
+// IsExceptionCode returns whether err is a Clickhouse exception with one of the given codes.
+func IsExceptionCode(err error, codes ...int32) bool {
+	if err == nil {
+		return false
+	}
+	code, ok := exceptionCode(err)
+	if !ok {
+		return false
+	}
+	for _, expected := range codes {
+		if code == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryableError classifies errors into transient ones, like network failures or an overloaded
+// server, and fatal ones, like syntax errors or missing privileges, which fail at once.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if code, ok := exceptionCode(err); ok {
+		_, retryable := retryableExceptionCodes[code]
+		return retryable
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, driver.ErrBadConn)
+}
+
+// IsRetryableExecError classifies the errors of statements run with Exec, like DDL or grants, which are not
+// idempotent: only the exception codes of statements refused by the server are retried, network errors and
+// timeouts are not as the statement outcome is unknown. Idempotent statements are classified by IsRetryableError.
+func IsRetryableExecError(err error) bool {
+	if err == nil {
+		return false
+	}
+	code, ok := exceptionCode(err)
+	if !ok {
+		return false
+	}
+	_, retryable := execRetryableExceptionCodes[code]
+	return retryable
+}
+
+// idempotentStatementRegexes match the statements which can be run again with the same outcome, like
+// CREATE ... IF NOT EXISTS, CREATE OR REPLACE or DROP ... IF EXISTS, so they are retried after any transient error.
+var idempotentStatementRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)^\s*(?:CREATE|ATTACH)(?:\s+OR\s+REPLACE)?(?:\s+TEMPORARY)?\s+(?:\w+\s+){1,2}IF\s+NOT\s+EXISTS\b`),
+	regexp.MustCompile(`(?is)^\s*CREATE\s+OR\s+REPLACE\b`),
+	regexp.MustCompile(`(?is)^\s*(?:DROP|DETACH)(?:\s+TEMPORARY)?\s+(?:\w+\s+){1,2}IF\s+EXISTS\b`),
+}
+
+// isIdempotentStatement returns whether running query again after it may have been applied has the same outcome.
+func isIdempotentStatement(query string) bool {
+	for _, regex := range idempotentStatementRegexes {
+		if regex.MatchString(query) {
+			return true
+		}
+	}
+	return false
+}
+
+// statementKinds are the statements whose kind includes the object they apply to, e.g. CREATE USER.
+var statementKinds = map[string]bool{"CREATE": true, "ALTER": true, "DROP": true, "ATTACH": true, "DETACH": true, "RENAME": true}
+
+// statementKind returns the leading keywords of a statement, e.g. "CREATE TABLE" or "SELECT". Only the kind
+// is logged, statements may hold secrets like the password of CREATE USER ... IDENTIFIED BY.
+func statementKind(query string) string {
+	words := strings.Fields(strings.ToUpper(query))
+	if len(words) == 0 {
+		return ""
+	}
+	kind := words[0]
+	if statementKinds[kind] {
+		for _, word := range words[1:] {
+			if word != "OR" && word != "REPLACE" && word != "TEMPORARY" {
+				return kind + " " + word
+			}
+		}
+	}
+	return kind
+}
+
+// errorSummary describes Clickhouse exceptions by their code only, as their message may quote the failed statement.
+func errorSummary(err error) string {
+	if code, ok := exceptionCode(err); ok {
+		return fmt.Sprintf("code %d", code)
+	}
+	return err.Error()
+}
+
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// backoff returns the delay before the given retry, growing exponentially up to MaxBackoff,
+// with jitter so clients failing at the same time don't retry at the same time.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	// The delay is capped before shifting, InitialBackoff << retry may overflow
+	delay := p.MaxBackoff
+	if retry < 63 && p.InitialBackoff <= p.MaxBackoff>>retry {
+		delay = p.InitialBackoff << retry
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// run calls operation until it succeeds, fails with an error retryable doesn't accept or the retries are exhausted.
+func (p RetryPolicy) run(ctx context.Context, query string, retryable func(error) bool, operation func() error) error {
+	kind := statementKind(query)
+	for attempt := 0; ; attempt++ {
+		tflog.Debug(ctx, "Running Clickhouse query", map[string]interface{}{"attempt": attempt + 1, "statement": kind})
+		err := operation()
+		if err == nil || !retryable(err) || attempt >= p.MaxRetries {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		tflog.Warn(ctx, "Retrying Clickhouse query after a transient error", map[string]interface{}{
+			"attempt":   attempt + 1,
+			"error":     errorSummary(err),
+			"delay":     delay.String(),
+			"statement": kind,
+		})
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// RetryConn retries the queries failing with transient errors following its retry policy, statements run
+// with Exec are only retried when they were refused by the server, unless they are idempotent.
+type RetryConn struct {
+	chdriver.Conn
+	Policy RetryPolicy
+}
+
+func (c *RetryConn) Exec(ctx context.Context, query string, args ...any) error {
+	retryable := IsRetryableExecError
+	if isIdempotentStatement(query) {
+		retryable = IsRetryableError
+	}
+	return c.Policy.run(ctx, query, retryable, func() error {
+		return c.Conn.Exec(ctx, query, args...)
+	})
+}
+
+func (c *RetryConn) Query(ctx context.Context, query string, args ...any) (chdriver.Rows, error) {
+	var rows chdriver.Rows
+	err := c.Policy.run(ctx, query, IsRetryableError, func() error {
+		var err error
+		rows, err = c.Conn.Query(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (c *RetryConn) QueryRow(ctx context.Context, query string, args ...any) chdriver.Row {
+	var row chdriver.Row
+	// The row error is returned so it's retried as well, it's still available through row.Err()
+	_ = c.Policy.run(ctx, query, IsRetryableError, func() error {
+		row = c.Conn.QueryRow(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+func (c *RetryConn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	return c.Policy.run(ctx, query, IsRetryableError, func() error {
+		return c.Conn.Select(ctx, dest, query, args...)
+	})
+}
+
+func (c *RetryConn) Ping(ctx context.Context) error {
+	return c.Policy.run(ctx, "ping", IsRetryableError, func() error {
+		return c.Conn.Ping(ctx)
+	})
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	chdriver "github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+type failingConn struct {
+	chdriver.Conn
+	errors []error
+	calls  int
+}
+
+func (c *failingConn) Exec(ctx context.Context, query string, args ...any) error {
+	c.calls++
+	if c.calls <= len(c.errors) {
+		return c.errors[c.calls-1]
+	}
+	return nil
+}
+
+func (c *failingConn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	return c.Exec(ctx, query, args...)
+}
+
+func TestIsRetryableError(t *testing.T) {
+	var tests = []struct {
+		err      error
+		expected bool
+	}{
+		{&proto.Exception{Code: 202, Name: "DB::Exception"}, true},
+		{fmt.Errorf("creating table: %w", &proto.Exception{Code: 999}), true},
+		{&proto.Exception{Code: 62, Name: "DB::Exception"}, false},
+		{errors.New("clickhouse [execute]:: 503 code: Code: 202. DB::Exception: Too many simultaneous queries"), true},
+		{errors.New("clickhouse [execute]:: 500 code: Code: 497. DB::Exception: Not enough privileges"), false},
+		{io.EOF, true},
+		{fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{context.DeadlineExceeded, false},
+		{errors.New("unexpected error"), false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if actual := IsRetryableError(test.err); actual != test.expected {
+			t.Errorf("IsRetryableError(%v) = %v; want %v", test.err, actual, test.expected)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	var tests = []struct {
+		retry    int
+		expected time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 5 * time.Second},
+		{64, 5 * time.Second},
+	}
+	for _, test := range tests {
+		delay := policy.backoff(test.retry)
+		if delay < test.expected/2 || delay > test.expected {
+			t.Errorf("backoff(%d) = %v; want between %v and %v", test.retry, delay, test.expected/2, test.expected)
+		}
+	}
+
+	// InitialBackoff << retry overflows for these retries
+	long := RetryPolicy{MaxRetries: 40, InitialBackoff: 10 * time.Second, MaxBackoff: time.Hour}
+	for _, retry := range []int{30, 31, 40} {
+		if delay := long.backoff(retry); delay < 30*time.Minute || delay > time.Hour {
+			t.Errorf("backoff(%d) = %v; want between %v and %v", retry, delay, 30*time.Minute, time.Hour)
+		}
+	}
+}
+
+func TestRetryConnExec(t *testing.T) {
+	transient := &proto.Exception{Code: 202}
+	fatal := &proto.Exception{Code: 62}
+	policy := RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	var tests = []struct {
+		errors        []error
+		expectedErr   error
+		expectedCalls int
+	}{
+		{nil, nil, 1},
+		{[]error{transient, transient}, nil, 3},
+		{[]error{transient, transient, transient}, transient, 3},
+		{[]error{fatal}, fatal, 1},
+		{[]error{transient, fatal}, fatal, 2},
+		// The statement may have been applied before the connection dropped, retrying a CREATE would fail
+		// with TABLE_ALREADY_EXISTS
+		{[]error{io.EOF}, io.EOF, 1},
+		{[]error{&proto.Exception{Code: 159}}, &proto.Exception{Code: 159}, 1},
+	}
+	for _, test := range tests {
+		conn := &failingConn{errors: test.errors}
+		retryConn := &RetryConn{Conn: conn, Policy: policy}
+		err := retryConn.Exec(context.Background(), "CREATE DATABASE db")
+		if !reflect.DeepEqual(err, test.expectedErr) || conn.calls != test.expectedCalls {
+			t.Errorf("Exec with errors %v returned %v after %d calls; want %v after %d calls",
+				test.errors, err, conn.calls, test.expectedErr, test.expectedCalls)
+		}
+	}
+}
+
+func TestRetryConnExecIdempotent(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	timeout := &proto.Exception{Code: 159}
+	var tests = []struct {
+		query         string
+		errors        []error
+		expectedCalls int
+	}{
+		{"CREATE TABLE IF NOT EXISTS db.t (a UInt8) ENGINE = Memory", []error{io.EOF}, 2},
+		{"create materialized view if not exists db.mv TO db.t AS SELECT 1", []error{timeout}, 2},
+		{"CREATE OR REPLACE VIEW db.v AS SELECT 1", []error{&proto.Exception{Code: 999}, io.EOF}, 3},
+		{"DROP TABLE IF EXISTS db.t ON CLUSTER c SYNC", []error{timeout}, 2},
+		{"DROP ROLE IF EXISTS `reader`", []error{io.EOF}, 2},
+		{"CREATE TABLE db.t (a UInt8) COMMENT 'IF NOT EXISTS' ENGINE = Memory", []error{io.EOF}, 1},
+		{"DROP TABLE db.t", []error{timeout}, 1},
+		{"CREATE TABLE IF NOT EXISTS db.t (a UInt8) ENGINE = Memory", []error{&proto.Exception{Code: 62}}, 1},
+	}
+	for _, test := range tests {
+		conn := &failingConn{errors: test.errors}
+		retryConn := &RetryConn{Conn: conn, Policy: policy}
+		_ = retryConn.Exec(context.Background(), test.query)
+		if conn.calls != test.expectedCalls {
+			t.Errorf("Exec(%q) with errors %v called %d times; want %d", test.query, test.errors, conn.calls, test.expectedCalls)
+		}
+	}
+}
+
+func TestRetryConnSelect(t *testing.T) {
+	conn := &failingConn{errors: []error{io.EOF, &proto.Exception{Code: 159}}}
+	retryConn := &RetryConn{Conn: conn, Policy: RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}}
+	if err := retryConn.Select(context.Background(), nil, "SELECT 1"); err != nil || conn.calls != 3 {
+		t.Errorf("expected reads to be retried on network errors and timeouts, got %v after %d calls", err, conn.calls)
+	}
+}
+
+func TestStatementKind(t *testing.T) {
+	var tests = []struct {
+		query    string
+		expected string
+	}{
+		{"CREATE USER `u` IDENTIFIED WITH sha256_password BY 'secret'", "CREATE USER"},
+		{"create or replace dictionary db.d (id UInt64) PRIMARY KEY id SOURCE(CLICKHOUSE(PASSWORD 'secret'))", "CREATE DICTIONARY"},
+		{"  ALTER TABLE db.t ADD COLUMN a UInt8", "ALTER TABLE"},
+		{"GRANT SELECT ON db.* TO u", "GRANT"},
+		{"SELECT name FROM system.tables", "SELECT"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if actual := statementKind(test.query); actual != test.expected {
+			t.Errorf("statementKind(%q) = %q; want %q", test.query, actual, test.expected)
+		}
+	}
+}
+
+func TestRetryConnCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	transient := &proto.Exception{Code: 202}
+	conn := &failingConn{errors: []error{transient, transient}}
+	retryConn := &RetryConn{Conn: conn, Policy: RetryPolicy{MaxRetries: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}}
+	if err := retryConn.Exec(ctx, "SELECT 1"); err != transient || conn.calls != 1 {
+		t.Errorf("expected to stop retrying once the context is done, got %v after %d calls", err, conn.calls)
+	}
+}
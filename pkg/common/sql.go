@@ -0,0 +1,487 @@
+package common
+
+import (
+	"strings"
+	"unicode"
+)
+
+var identifierEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+var stringEscaper = strings.NewReplacer("\\", "\\\\", "'", "\\'")
+
+// QuoteIdentifier backtick-quotes a database object name, so names with dashes, spaces or reserved words
+// can be used safely, e.g. my-table becomes `my-table`.
+func QuoteIdentifier(name string) string {
+	return "`" + identifierEscaper.Replace(name) + "`"
+}
+
+// QuoteIdentifiers backtick-quotes every name of a list.
+func QuoteIdentifiers(names []string) []string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, QuoteIdentifier(name))
+	}
+	return quoted
+}
+
+// QualifiedName returns the quoted <database>.<name> reference to a table like object.
+func QualifiedName(database string, name string) string {
+	return QuoteIdentifier(database) + "." + QuoteIdentifier(name)
+}
+
+// QuoteString converts a value into a string literal escaping quotes and backslashes,
+// e.g. it's "ok" becomes 'it\'s "ok"'.
+func QuoteString(value string) string {
+	return "'" + stringEscaper.Replace(value) + "'"
+}
+
+// QuoteCluster quotes a cluster name for ON CLUSTER clauses. Names already written as string literals,
+// like '{cluster}' to use the server macro, are kept as they are.
+func QuoteCluster(cluster string) string {
+	if len(cluster) >= 2 && strings.HasPrefix(cluster, "'") && strings.HasSuffix(cluster, "'") {
+		return cluster
+	}
+	return QuoteIdentifier(cluster)
+}
+
+// TrimQuery removes the surrounding whitespaces and the trailing semicolon of a query embedded in a statement,
+// and ends it with a line break so a trailing SQL comment doesn't swallow the clauses following it.
+func TrimQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), ";") + "\n"
+}
+
+// queryKeywords are the keywords upper cased by NormalizeQuery, identifiers are case sensitive so any other
+// word keeps its case.
+var queryKeywords = map[string]bool{
+	"ALL": true, "AND": true, "ANY": true, "ARRAY": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true,
+	"CASE": true, "CROSS": true, "DESC": true, "DISTINCT": true, "ELSE": true, "END": true, "EXCEPT": true,
+	"EXISTS": true, "FALSE": true, "FINAL": true, "FROM": true, "FULL": true, "GLOBAL": true, "GROUP": true,
+	"HAVING": true, "ILIKE": true, "IN": true, "INNER": true, "INTERSECT": true, "INTERVAL": true, "IS": true,
+	"JOIN": true, "LEFT": true, "LIKE": true, "LIMIT": true, "NOT": true, "NULL": true, "NULLS": true,
+	"OFFSET": true, "ON": true, "OR": true, "ORDER": true, "OUTER": true, "OVER": true, "PARTITION": true,
+	"PREWHERE": true, "RIGHT": true, "SAMPLE": true, "SELECT": true, "SEMI": true, "SETTINGS": true,
+	"THEN": true, "TOTALS": true, "TRUE": true, "UNION": true, "USING": true, "WHEN": true, "WHERE": true,
+	"WITH": true,
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenEOF queryTokenKind = iota
+	queryTokenWord
+	queryTokenKeyword
+	queryTokenLiteral
+	queryTokenOperator
+	queryTokenPunctuation
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// queryOperators are the operators made of symbols, longest first so "<=" isn't read as "<" and "=".
+var queryOperators = []string{"->", "||", "<=", ">=", "!=", "<>", "==", "::", "+", "-", "*", "/", "%", "=", "<", ">", "?", ":"}
+
+// queryOperatorAliases maps the operators Clickhouse rewrites to the form it stores.
+var queryOperatorAliases = map[string]string{"==": "=", "<>": "!="}
+
+func isWordRune(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// tokenizeQuery splits a query into tokens, dropping comments and the identifier quotes.
+func tokenizeQuery(query string) []queryToken {
+	var tokens []queryToken
+	runes := []rune(query)
+
+	// quoted returns the end of the quoted text starting at i, skipping escaped and doubled quotes
+	quoted := func(i int) int {
+		quote := runes[i]
+		for i++; i < len(runes); i++ {
+			if runes[i] == '\\' {
+				i++
+			} else if runes[i] == quote {
+				if i+1 < len(runes) && runes[i+1] == quote {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+		return len(runes)
+	}
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := i + 2
+			for end+1 < len(runes) && !(runes[end] == '*' && runes[end+1] == '/') {
+				end++
+			}
+			i = end + 2
+		case c == '\'':
+			end := quoted(i)
+			tokens = append(tokens, queryToken{kind: queryTokenLiteral, text: string(runes[i:end])})
+			i = end
+		case c == '`' || c == '"':
+			end := quoted(i)
+			name := string(runes[i+1 : end-1])
+			tokens = append(tokens, queryToken{kind: queryTokenWord, text: name})
+			i = end
+		case c == '{':
+			// Query parameters like {x:UInt32}
+			end := i
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end < len(runes) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenLiteral, text: string(runes[i:end])})
+			i = end
+		case unicode.IsDigit(c):
+			end := i
+			for end < len(runes) && (isWordRune(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenLiteral, text: string(runes[i:end])})
+			i = end
+		case isWordRune(c):
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			if queryKeywords[strings.ToUpper(word)] {
+				tokens = append(tokens, queryToken{kind: queryTokenKeyword, text: strings.ToUpper(word)})
+			} else {
+				tokens = append(tokens, queryToken{kind: queryTokenWord, text: word})
+			}
+			i = end
+		default:
+			token := queryToken{kind: queryTokenPunctuation, text: string(c)}
+			for _, operator := range queryOperators {
+				if i+len(operator) <= len(runes) && string(runes[i:i+len(operator)]) == operator {
+					token = queryToken{kind: queryTokenOperator, text: operator}
+					if alias, ok := queryOperatorAliases[operator]; ok {
+						token.text = alias
+					}
+					i += len(operator) - 1
+					break
+				}
+			}
+			tokens = append(tokens, token)
+			i++
+		}
+	}
+
+	for len(tokens) > 0 && tokens[len(tokens)-1].text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return append(tokens, queryToken{kind: queryTokenEOF})
+}
+
+// Operator precedences, from the loosest to the tightest binding
+const (
+	precedenceLambda = iota + 1
+	precedenceOr
+	precedenceAnd
+	precedenceNot
+	precedenceComparison
+	precedenceConcat
+	precedenceAdditive
+	precedenceMultiplicative
+	precedenceCast
+)
+
+var binaryPrecedences = map[string]int{
+	"->": precedenceLambda, "OR": precedenceOr, "AND": precedenceAnd,
+	"=": precedenceComparison, "!=": precedenceComparison, "<": precedenceComparison, ">": precedenceComparison,
+	"<=": precedenceComparison, ">=": precedenceComparison, "LIKE": precedenceComparison, "ILIKE": precedenceComparison,
+	"IN": precedenceComparison, "BETWEEN": precedenceComparison, "IS": precedenceComparison,
+	"||": precedenceConcat, "+": precedenceAdditive, "-": precedenceAdditive,
+	"*": precedenceMultiplicative, "/": precedenceMultiplicative, "%": precedenceMultiplicative, "::": precedenceCast,
+}
+
+// queryNormalizer rewrites the expressions of a query fully parenthesized, so the grouping parentheses written
+// by users and the ones Clickhouse adds when it stores the query, e.g. ((k * x) + b), don't matter.
+type queryNormalizer struct {
+	tokens   []queryToken
+	current  int
+	database string
+}
+
+func (n *queryNormalizer) peek() queryToken {
+	return n.tokens[n.current]
+}
+
+func (n *queryNormalizer) peekAt(offset int) queryToken {
+	if n.current+offset >= len(n.tokens) {
+		return n.tokens[len(n.tokens)-1]
+	}
+	return n.tokens[n.current+offset]
+}
+
+func (n *queryNormalizer) next() queryToken {
+	token := n.tokens[n.current]
+	if token.kind != queryTokenEOF {
+		n.current++
+	}
+	return token
+}
+
+func (n *queryNormalizer) startsOperand(token queryToken) bool {
+	switch token.kind {
+	case queryTokenWord, queryTokenLiteral:
+		return true
+	case queryTokenKeyword:
+		return token.text == "NOT" || token.text == "CASE" || token.text == "NULL" || token.text == "TRUE" ||
+			token.text == "FALSE" || token.text == "INTERVAL"
+	case queryTokenOperator:
+		return token.text == "-" || token.text == "+" || token.text == "*"
+	case queryTokenPunctuation:
+		return token.text == "(" || token.text == "["
+	}
+	return false
+}
+
+// sequence normalizes the tokens up to the closing one (excluded), parsing the expressions found on the way.
+func (n *queryNormalizer) sequence(closing string) string {
+	var normalized strings.Builder
+	for {
+		token := n.peek()
+		if token.kind == queryTokenEOF || (closing != "" && token.text == closing && token.kind != queryTokenLiteral) {
+			return normalized.String()
+		}
+		if normalized.Len() > 0 && token.text != "," {
+			normalized.WriteString(" ")
+		}
+		if n.startsOperand(token) {
+			normalized.WriteString(n.expression(0))
+			continue
+		}
+		n.next()
+		if token.text == "(" || token.text == "[" {
+			// Parentheses not holding an operand, like the ones of OVER (PARTITION BY ...)
+			end := map[string]string{"(": ")", "[": "]"}[token.text]
+			normalized.WriteString(token.text + n.sequence(end) + n.next().text)
+			continue
+		}
+		normalized.WriteString(token.text)
+	}
+}
+
+// list normalizes the comma separated items up to the closing token, consuming it.
+func (n *queryNormalizer) list(closing string) []string {
+	items := make([]string, 0)
+	for {
+		token := n.peek()
+		if token.kind == queryTokenEOF {
+			return items
+		}
+		if token.text == closing {
+			n.next()
+			return items
+		}
+		item := ""
+		if n.startsOperand(token) && token.text != "*" {
+			item = n.expression(0)
+		}
+		if next := n.peek(); next.text != "," && next.text != closing {
+			rest := n.sequenceUntilItemEnd(closing)
+			item = strings.TrimSpace(item + " " + rest)
+		}
+		items = append(items, item)
+		if n.peek().text == "," {
+			n.next()
+		}
+	}
+}
+
+// sequenceUntilItemEnd normalizes the rest of a list item, e.g. "DISTINCT x" or "SELECT ...", up to the next
+// comma or the closing token of the list.
+func (n *queryNormalizer) sequenceUntilItemEnd(closing string) string {
+	var normalized strings.Builder
+	for {
+		token := n.peek()
+		if token.kind == queryTokenEOF || token.text == closing || token.text == "," {
+			return normalized.String()
+		}
+		if normalized.Len() > 0 {
+			normalized.WriteString(" ")
+		}
+		if n.startsOperand(token) {
+			normalized.WriteString(n.expression(0))
+			continue
+		}
+		n.next()
+		if token.text == "(" || token.text == "[" {
+			end := map[string]string{"(": ")", "[": "]"}[token.text]
+			normalized.WriteString(token.text + n.sequence(end) + n.next().text)
+			continue
+		}
+		normalized.WriteString(token.text)
+	}
+}
+
+// binaryOperator returns the binary operator starting at the current token, its precedence and its length in
+// tokens, handling the negated ones like NOT IN.
+func (n *queryNormalizer) binaryOperator() (string, int, int) {
+	token := n.peek()
+	if token.kind != queryTokenOperator && token.kind != queryTokenKeyword {
+		return "", 0, 0
+	}
+	if token.text == "NOT" {
+		negated := n.peekAt(1)
+		if negated.kind == queryTokenKeyword && (negated.text == "IN" || negated.text == "LIKE" || negated.text == "ILIKE" || negated.text == "BETWEEN") {
+			return "NOT " + negated.text, precedenceComparison, 2
+		}
+		return "", 0, 0
+	}
+	if token.text == "GLOBAL" && n.peekAt(1).text == "IN" {
+		return "GLOBAL IN", precedenceComparison, 2
+	}
+	if token.text == "GLOBAL" && n.peekAt(1).text == "NOT" && n.peekAt(2).text == "IN" {
+		return "GLOBAL NOT IN", precedenceComparison, 3
+	}
+	precedence, ok := binaryPrecedences[token.text]
+	if !ok {
+		return "", 0, 0
+	}
+	return token.text, precedence, 1
+}
+
+// expression parses an expression whose operators bind at least as tight as minPrecedence.
+func (n *queryNormalizer) expression(minPrecedence int) string {
+	left := n.unary()
+	for {
+		operator, precedence, length := n.binaryOperator()
+		if length == 0 || precedence < minPrecedence {
+			return left
+		}
+		n.current += length
+
+		switch {
+		case operator == "IS":
+			negated := ""
+			if n.peek().text == "NOT" {
+				n.next()
+				negated = " NOT"
+			}
+			left = "(" + left + " IS" + negated + " " + n.next().text + ")"
+		case strings.HasSuffix(operator, "BETWEEN"):
+			low := n.expression(precedence + 1)
+			if n.peek().text == "AND" {
+				n.next()
+			}
+			high := n.expression(precedence + 1)
+			left = "(" + left + " " + operator + " " + low + " AND " + high + ")"
+		case operator == "->":
+			// Lambdas are right associative
+			left = "(" + left + " -> " + n.expression(precedence) + ")"
+		default:
+			left = "(" + left + " " + operator + " " + n.expression(precedence+1) + ")"
+		}
+	}
+}
+
+func (n *queryNormalizer) unary() string {
+	token := n.peek()
+	switch {
+	case token.kind == queryTokenKeyword && token.text == "NOT":
+		n.next()
+		return "(NOT " + n.expression(precedenceComparison) + ")"
+	case token.kind == queryTokenOperator && token.text == "-":
+		n.next()
+		return "(-" + n.unary() + ")"
+	case token.kind == queryTokenOperator && token.text == "+":
+		n.next()
+		return n.unary()
+	}
+	return n.postfix(n.primary())
+}
+
+func (n *queryNormalizer) postfix(operand string) string {
+	for n.peek().text == "[" {
+		n.next()
+		operand += "[" + strings.Join(n.list("]"), ", ") + "]"
+	}
+	return operand
+}
+
+func (n *queryNormalizer) primary() string {
+	token := n.peek()
+	if !n.startsOperand(token) {
+		return ""
+	}
+	n.next()
+
+	switch {
+	case token.text == "(" && token.kind == queryTokenPunctuation:
+		if first := n.peek(); first.text == "SELECT" || first.text == "WITH" {
+			subquery := n.sequence(")")
+			n.next()
+			return "(" + subquery + ")"
+		}
+		items := n.list(")")
+		if len(items) == 1 {
+			// Grouping parentheses, the expression inside is already parenthesized if needed
+			return items[0]
+		}
+		return "(" + strings.Join(items, ", ") + ")"
+	case token.text == "[" && token.kind == queryTokenPunctuation:
+		return "[" + strings.Join(n.list("]"), ", ") + "]"
+	case token.text == "CASE":
+		body := n.sequence("END")
+		n.next()
+		return "CASE " + body + " END"
+	case token.text == "INTERVAL":
+		// Clickhouse stores INTERVAL 1 DAY as toIntervalDay(1)
+		value := n.unary()
+		unit := strings.ToLower(n.next().text)
+		if unit == "" {
+			return "INTERVAL " + value
+		}
+		return "toInterval" + strings.ToUpper(unit[:1]) + strings.TrimSuffix(unit[1:], "s") + "(" + value + ")"
+	case token.kind == queryTokenWord:
+		parts := []string{token.text}
+		for n.peek().text == "." && (n.peekAt(1).kind == queryTokenWord || n.peekAt(1).kind == queryTokenLiteral || n.peekAt(1).text == "*") {
+			n.next()
+			parts = append(parts, n.next().text)
+		}
+		if len(parts) > 1 && n.database != "" && parts[0] == n.database {
+			parts = parts[1:]
+		}
+		name := strings.Join(parts, ".")
+		// Function calls, parametric aggregate functions have two argument lists like quantile(0.9)(x)
+		for n.peek().text == "(" {
+			n.next()
+			name += "(" + strings.Join(n.list(")"), ", ") + ")"
+		}
+		return name
+	}
+	return token.text
+}
+
+// NormalizeQuery returns a canonical form of a query so the one written in a configuration can be
+// compared with the one Clickhouse stores, which is reformatted: comments, identifier quotes,
+// redundant whitespace and parentheses and the trailing semicolon are removed, expressions are fully
+// parenthesized, keywords are upper cased (identifiers and string literals are kept as they are) and the
+// default database prefix is dropped.
+func NormalizeQuery(query string, database string) string {
+	normalizer := queryNormalizer{tokens: tokenizeQuery(query), database: database}
+	normalized := normalizer.sequence("")
+	return normalized
+}
+
+// QueriesEquivalent tells whether two queries are the same once normalized, see NormalizeQuery.
+func QueriesEquivalent(a string, b string, database string) bool {
+	return NormalizeQuery(a, database) == NormalizeQuery(b, database)
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// secretRegex matches the secrets written in statements, like the password of CREATE USER ... IDENTIFIED BY
+// or the one of a dictionary source, keeping the clause introducing them in the first group.
+var secretRegex = regexp.MustCompile(`(?i)(\bIDENTIFIED\s+(?:WITH\s+\w+\s+)?BY\s+|\bPASSWORD\s+)'(?:[^'\\]|\\.|'')*'`)
+
+// RedactSecrets replaces the passwords of a statement by a placeholder, so it can be shared for review.
+func RedactSecrets(query string) string {
+	return secretRegex.ReplaceAllString(query, "$1'[REDACTED]'")
+}
+
+// SQLOutputExecutor appends the statements run with Exec (DDL, grants...) to a file instead of
+// running them, so they can be reviewed before being applied. Queries still read from Clickhouse.
+// Resources wrapped by WithSQLOutput fail once their statements are written, so their state isn't changed.
+type SQLOutputExecutor struct {
+	Executor
+	Path string
+
+	mutex sync.Mutex
+}
+
+type sqlOutputKey struct{}
+
+// sqlOutputWritten counts the statements written to the SQL output file by a resource operation.
+type sqlOutputWritten struct {
+	statements int
+	path       string
+}
+
+func (e *SQLOutputExecutor) Exec(ctx context.Context, query string, args ...any) error {
+	if len(args) > 0 {
+		return fmt.Errorf("writing statement to %s: bound parameters are not supported", e.Path)
+	}
+
+	// Resources are applied concurrently, statements are written one at a time so they don't interleave
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	file, err := os.OpenFile(e.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening SQL output file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%s;\n\n", RedactSecrets(query)); err != nil {
+		return fmt.Errorf("writing statement to %s: %v", e.Path, err)
+	}
+	if written, ok := ctx.Value(sqlOutputKey{}).(*sqlOutputWritten); ok {
+		written.statements++
+		written.path = e.Path
+	}
+	tflog.Info(ctx, "Statement written to the SQL output file instead of being run", map[string]interface{}{"path": e.Path, "statement": statementKind(query)})
+	return nil
+}
+
+// WithSQLOutput makes the create, update and delete of a resource fail once they wrote their statements to the
+// SQL output file: nothing was applied, so the resource is not created and its state is left as it was.
+func WithSQLOutput(resource *schema.Resource) *schema.Resource {
+	if create := resource.CreateContext; create != nil {
+		resource.CreateContext = func(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+			written := &sqlOutputWritten{}
+			diags := create(context.WithValue(ctx, sqlOutputKey{}, written), d, meta)
+			if written.statements == 0 {
+				return diags
+			}
+			d.SetId("")
+			return append(diags, written.diagnostic())
+		}
+	}
+	if update := resource.UpdateContext; update != nil {
+		resource.UpdateContext = func(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+			written := &sqlOutputWritten{}
+			diags := update(context.WithValue(ctx, sqlOutputKey{}, written), d, meta)
+			if written.statements == 0 {
+				return diags
+			}
+			// Keeps the previous state instead of the planned one
+			d.Partial(true)
+			return append(diags, written.diagnostic())
+		}
+	}
+	if remove := resource.DeleteContext; remove != nil {
+		resource.DeleteContext = func(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+			written := &sqlOutputWritten{}
+			diags := remove(context.WithValue(ctx, sqlOutputKey{}, written), d, meta)
+			if written.statements == 0 {
+				return diags
+			}
+			return append(diags, written.diagnostic())
+		}
+	}
+	return resource
+}
+
+func (w *sqlOutputWritten) diagnostic() diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "statements written to the SQL output file instead of being run",
+		Detail:   fmt.Sprintf("%d statements were appended to %s for review, passwords redacted. Nothing was applied, so the resource state is left unchanged.", w.statements, w.path),
+	}
+}
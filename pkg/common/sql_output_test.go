@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSQLOutputExecutor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.sql")
+	executor := &SQLOutputExecutor{Path: path}
+
+	for _, query := range []string{"CREATE DATABASE `db`", "DROP TABLE `db`.`events`"} {
+		if err := executor.Exec(context.Background(), query); err != nil {
+			t.Fatalf("Exec(%q) failed: %v", query, err)
+		}
+	}
+	if err := executor.Exec(context.Background(), "SELECT ?", 1); err == nil {
+		t.Errorf("Exec with bound parameters expected to fail")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading SQL output file: %v", err)
+	}
+	expected := "CREATE DATABASE `db`;\n\nDROP TABLE `db`.`events`;\n\n"
+	if string(content) != expected {
+		t.Errorf("SQL output file content = %q, expected %q", content, expected)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"CREATE USER `u` IDENTIFIED WITH sha256_password BY 'it''s secret'", "CREATE USER `u` IDENTIFIED WITH sha256_password BY '[REDACTED]'"},
+		{"ALTER USER `u` IDENTIFIED BY 'secret' HOST ANY", "ALTER USER `u` IDENTIFIED BY '[REDACTED]' HOST ANY"},
+		{"CREATE DICTIONARY d (id UInt64) PRIMARY KEY id SOURCE(CLICKHOUSE(USER 'u' PASSWORD '1234' TABLE 't'))", "CREATE DICTIONARY d (id UInt64) PRIMARY KEY id SOURCE(CLICKHOUSE(USER 'u' PASSWORD '[REDACTED]' TABLE 't'))"},
+		{"CREATE TABLE t (password String) ENGINE = Memory", "CREATE TABLE t (password String) ENGINE = Memory"},
+	}
+	for _, test := range tests {
+		if actual := RedactSecrets(test.query); actual != test.expected {
+			t.Errorf("RedactSecrets(%q) = %q, expected %q", test.query, actual, test.expected)
+		}
+	}
+}
+
+func TestWithSQLOutput(t *testing.T) {
+	executor := &SQLOutputExecutor{Path: filepath.Join(t.TempDir(), "plan.sql")}
+	resource := WithSQLOutput(&schema.Resource{
+		Schema: map[string]*schema.Schema{"name": {Type: schema.TypeString, Optional: true}},
+		CreateContext: func(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+			if err := executor.Exec(ctx, "CREATE DATABASE `db`"); err != nil {
+				return diag.FromErr(err)
+			}
+			d.SetId("db")
+			return nil
+		},
+	})
+
+	d := resource.TestResourceData()
+	diags := resource.CreateContext(context.Background(), d, nil)
+	if !diags.HasError() || d.Id() != "" {
+		t.Errorf("create expected to fail without id once its statements are written, got %v and id %q", diags, d.Id())
+	}
+}
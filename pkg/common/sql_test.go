@@ -0,0 +1,84 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"events":      "`events`",
+		"my-table":    "`my-table`",
+		"order":       "`order`",
+		"back`tick":   "`back\\`tick`",
+		"back\\slash": "`back\\\\slash`",
+		"with space":  "`with space`",
+		"":            "``",
+	}
+
+	for name, expected := range tests {
+		if quoted := QuoteIdentifier(name); quoted != expected {
+			t.Errorf("QuoteIdentifier(%q) = %q, expected %q", name, quoted, expected)
+		}
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := map[string]string{
+		"password":            "'password'",
+		"it's":                "'it\\'s'",
+		"' OR 1=1 --":         "'\\' OR 1=1 --'",
+		"trailing\\":          "'trailing\\\\'",
+		`{"comment":"a 'b'"}`: `'{"comment":"a \'b\'"}'`,
+	}
+
+	for value, expected := range tests {
+		if quoted := QuoteString(value); quoted != expected {
+			t.Errorf("QuoteString(%q) = %q, expected %q", value, quoted, expected)
+		}
+	}
+}
+
+func TestGetClusterStatement(t *testing.T) {
+	tests := map[string]string{
+		"":            "",
+		"cluster":     "ON CLUSTER `cluster`",
+		"'{cluster}'": "ON CLUSTER '{cluster}'",
+	}
+
+	for cluster, expected := range tests {
+		if statement := GetClusterStatement(cluster); statement != expected {
+			t.Errorf("GetClusterStatement(%q) = %q, expected %q", cluster, statement, expected)
+		}
+	}
+}
+
+func TestQueriesEquivalent(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"select a, b from t where x = 'Some  Text'", "SELECT a, b FROM db.t WHERE x = 'Some  Text'", true},
+		{"SELECT `a`\n  FROM \"t\" -- all rows\n;", "SELECT a FROM db.t", true},
+		{"SELECT /* ids */ id FROM t WHERE x = {x:UInt32}", "SELECT id FROM db.t WHERE x = {x:UInt32}", true},
+		{"SELECT count() FROM t GROUP BY toDate(ts)", "SELECT count()\nFROM db.t\nGROUP BY toDate(ts)", true},
+		{"SELECT a FROM otherdb.t", "SELECT a FROM t", false},
+		{"SELECT a FROM t WHERE x = 'text'", "SELECT a FROM t WHERE x = 'TEXT'", false},
+		{"SELECT a FROM t", "SELECT b FROM t", false},
+		{"SELECT A FROM t", "SELECT a FROM t", false},
+		{"SELECT a FROM t WHERE a = 1 AND b = 2", "SELECT a\nFROM db.t\nWHERE (a = 1) AND (b = 2)", true},
+		{"SELECT k * x + b FROM t", "SELECT ((k * x) + b) FROM db.t", true},
+		{"SELECT (a + b) * c FROM t", "SELECT a + b * c FROM t", false},
+		{"SELECT a FROM t WHERE NOT a == 1 OR b <> 2", "SELECT a FROM t WHERE (NOT (a = 1)) OR (b != 2)", true},
+		{"SELECT x -> x + 1, (a, b) FROM t", "SELECT (x -> (x + 1)), (a, b) FROM t", true},
+		{"SELECT a FROM t WHERE ts > now() - INTERVAL 1 DAY", "SELECT a FROM t WHERE ts > (now() - toIntervalDay(1))", true},
+		{"SELECT a FROM t WHERE a IN (SELECT a FROM u)", "SELECT a FROM t WHERE a IN ((SELECT a FROM u))", true},
+		{"SELECT quantile(0.9)(x) FROM t", "SELECT quantile(0.9)(x) FROM db.t", true},
+	}
+
+	for _, test := range tests {
+		if equivalent := QueriesEquivalent(test.a, test.b, "db"); equivalent != test.expected {
+			t.Errorf("QueriesEquivalent(%q, %q) = %v, expected %v (%q, %q)", test.a, test.b, equivalent, test.expected,
+				NormalizeQuery(test.a, "db"), NormalizeQuery(test.b, "db"))
+		}
+	}
+}
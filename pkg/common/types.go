@@ -1,10 +1,24 @@
 package common
 
 import (
+	"context"
+	"time"
+
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
+// DefaultTimeout is the create, update and delete timeout of the resources without a timeouts block.
+const DefaultTimeout = 20 * time.Minute
+
+// Executor is the subset of the Clickhouse connection used by the resources and data sources,
+// narrow enough to be replaced by an in-memory fake in unit tests.
+type Executor interface {
+	Exec(ctx context.Context, query string, args ...any) error
+	Query(ctx context.Context, query string, args ...any) (driver.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) driver.Row
+}
+
 type ApiClient struct {
-	ClickhouseConnection *driver.Conn
+	ClickhouseConnection Executor
 	DefaultCluster       string
 }
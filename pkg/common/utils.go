@@ -1,37 +1,13 @@
 package common
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"strings"
 )
 
-func GetComment(comment string, cluster string) string {
-	storingComment := fmt.Sprintf(`{"comment":"%v","cluster":"%v"}`, comment, cluster)
-	storingComment = strings.Replace(storingComment, "'", "\\'", -1)
-	return storingComment
-}
-
-func UnmarshalComment(storedComment string) (comment string, cluster string, err error) {
-	storedComment = strings.Replace(storedComment, "\\'", "'", -1)
-
-	byteStreamComment := []byte(storedComment)
-
-	var dat map[string]interface{}
-
-	if err := json.Unmarshal(byteStreamComment, &dat); err != nil {
-		return "", "", err
-	}
-	comment = dat["comment"].(string)
-	cluster = dat["cluster"].(string)
-
-	return comment, cluster, err
-}
-
 func GetClusterStatement(cluster string) (clusterStatement string) {
 	if cluster != "" {
-		return fmt.Sprintf("ON CLUSTER %s", cluster)
+		return fmt.Sprintf("ON CLUSTER %s", QuoteCluster(cluster))
 	}
 	return ""
 }
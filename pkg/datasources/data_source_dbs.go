@@ -62,7 +62,7 @@ func DataSourceDbs() *schema.Resource {
 func dataSourceDbsRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	client := meta.(*common.ApiClient)
 	var diags diag.Diagnostics
-	conn := *client.ClickhouseConnection
+	conn := client.ClickhouseConnection
 
 	rows, err := conn.Query(ctx, "SELECT name, engine, data_path, metadata_path, uuid, comment FROM system.databases")
 	if err != nil {
@@ -76,13 +76,18 @@ func dataSourceDbsRead(ctx context.Context, d *schema.ResourceData, meta any) di
 		if err := rows.ScanStruct(&chDatabase); err != nil {
 			log.Fatal(err)
 		}
+		comment, _, err := common.UnmarshalComment(chDatabase.Comment)
+		if err != nil {
+			// Databases not created by the provider keep their comment as it is
+			comment = chDatabase.Comment
+		}
 		dbResource := map[string]interface{}{
 			"name":          chDatabase.Name,
 			"engine":        chDatabase.Engine,
 			"data_path":     chDatabase.DataPath,
 			"metadata_path": chDatabase.MetadataPath,
 			"uuid":          chDatabase.Uuid,
-			"comment":       chDatabase.Comment,
+			"comment":       comment,
 		}
 		dbResources = append(dbResources, dbResource)
 	}
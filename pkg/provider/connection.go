@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"strconv"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+var connectionOpenStrategies = map[string]clickhouse.ConnOpenStrategy{
+	"in_order":    clickhouse.ConnOpenInOrder,
+	"round_robin": clickhouse.ConnOpenRoundRobin,
+	// Addresses are shuffled once and then tried in order, clickhouse-go has no random strategy
+	"random": clickhouse.ConnOpenInOrder,
+}
+
+// buildAddress appends the default port to hosts given without it, e.g. "node1" becomes "node1:9000".
+func buildAddress(host string, port int) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// buildAddresses returns the addresses of the main host and the additional ones, shuffled for the random strategy.
+func buildAddresses(host string, additionalHosts []string, port int, strategy string) []string {
+	addresses := []string{buildAddress(host, port)}
+	for _, additionalHost := range additionalHosts {
+		addresses = append(addresses, buildAddress(additionalHost, port))
+	}
+	if strategy == "random" {
+		rand.Shuffle(len(addresses), func(i, j int) {
+			addresses[i], addresses[j] = addresses[j], addresses[i]
+		})
+	}
+	return addresses
+}
+
+// ddlRoutedConn sends the statements (DDL, grants...) to a coordinator node while the queries are spread over
+// the hosts of the main connection, following its failover strategy. The objects must then exist on every host,
+// i.e. be created ON CLUSTER or replicated, or reading them from another host would drop them from state.
+type ddlRoutedConn struct {
+	driver.Conn
+	ddl driver.Conn
+}
+
+func (c *ddlRoutedConn) Exec(ctx context.Context, query string, args ...any) error {
+	return c.ddl.Exec(ctx, query, args...)
+}
+
+func (c *ddlRoutedConn) Ping(ctx context.Context) error {
+	if err := c.Conn.Ping(ctx); err != nil {
+		return err
+	}
+	return c.ddl.Ping(ctx)
+}
+
+func (c *ddlRoutedConn) Close() error {
+	if err := c.ddl.Close(); err != nil {
+		return err
+	}
+	return c.Conn.Close()
+}
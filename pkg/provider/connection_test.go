@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+func TestBuildAddresses(t *testing.T) {
+	addresses := buildAddresses("node1", []string{"node2:9440", "10.0.0.3", "::1"}, 9000, "in_order")
+	expected := []string{"node1:9000", "node2:9440", "10.0.0.3:9000", "[::1]:9000"}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("buildAddresses() = %q, expected %q", addresses, expected)
+	}
+
+	shuffled := buildAddresses("node1", []string{"node2", "node3"}, 9000, "random")
+	sort.Strings(shuffled)
+	if !reflect.DeepEqual(shuffled, []string{"node1:9000", "node2:9000", "node3:9000"}) {
+		t.Errorf("random strategy must keep every address, got %q", shuffled)
+	}
+}
+
+type recordingConn struct {
+	driver.Conn
+	queries []string
+}
+
+func (c *recordingConn) Exec(ctx context.Context, query string, args ...any) error {
+	c.queries = append(c.queries, query)
+	return nil
+}
+
+func (c *recordingConn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	c.queries = append(c.queries, query)
+	return nil
+}
+
+func TestDDLRoutedConn(t *testing.T) {
+	main, ddl := &recordingConn{}, &recordingConn{}
+	conn := &ddlRoutedConn{Conn: main, ddl: ddl}
+
+	_ = conn.Exec(context.Background(), "CREATE TABLE db.t (a UInt8) ENGINE = Memory")
+	_ = conn.Select(context.Background(), nil, "SELECT name FROM system.tables")
+
+	if !reflect.DeepEqual(ddl.queries, []string{"CREATE TABLE db.t (a UInt8) ENGINE = Memory"}) {
+		t.Errorf("expected the statement on the ddl host, got %q", ddl.queries)
+	}
+	if !reflect.DeepEqual(main.queries, []string{"SELECT name FROM system.tables"}) {
+		t.Errorf("expected the read on the main hosts, got %q", main.queries)
+	}
+}
@@ -9,13 +9,20 @@ import (
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/datasources"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/db"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/dictionary"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/function"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/grant"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/materializedview"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/role"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/user"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/view"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/joho/godotenv"
 	"os"
+	"time"
 )
 
 func init() {
@@ -73,8 +80,29 @@ func New(version string) func() *schema.Provider {
 						return getEnvVar("TF_CLICKHOUSE_HOST")
 					},
 				},
+				"additional_hosts": {
+					Description: "Additional Clickhouse servers, as `host` or `host:port`, used for failover and load balancing along with `host`",
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"connection_open_strategy": {
+					Description:      "Strategy to pick the server a connection is opened to: `in_order` (failover in the order hosts are listed), `round_robin` or `random`",
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "in_order",
+					ValidateDiagFunc: validateConnectionOpenStrategy,
+				},
+				"ddl_host": {
+					Description: "Coordinator server, as `host` or `host:port`, that receives every DDL statement. Reads still go to `host` and `additional_hosts`, so objects must be created `ON CLUSTER` or replicated to be found",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+				},
 				"port": {
-					Description: "Clickhouse server native protocol port (TCP)",
+					Description: "Clickhouse server port, the native protocol port (9000/9440) or the HTTP interface one (8123/8443) depending on `protocol`",
 					Type:        schema.TypeInt,
 					Required:    true,
 					DefaultFunc: func() (any, error) {
@@ -82,23 +110,146 @@ func New(version string) func() *schema.Provider {
 					},
 				},
 				"secure": {
-					Description: "Clickhouse secure connection",
+					Description: "Clickhouse secure connection, TLS is configured with `ca_cert`, `client_cert`, `client_key`, `server_name`, `insecure_skip_verify` and `tls_min_version`",
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+				},
+				"ca_cert": {
+					Description: "CA bundle used to verify the server certificate, as a file path or PEM content. System roots are used when not provided",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+				},
+				"client_cert": {
+					Description: "Client certificate for mutual TLS authentication, as a file path or PEM content",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+				},
+				"client_key": {
+					Description: "Private key of the client certificate, as a file path or PEM content",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Default:     "",
+				},
+				"server_name": {
+					Description: "Server name sent for SNI and checked against the server certificate, the host is used when not provided",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+				},
+				"insecure_skip_verify": {
+					Description: "Skip the server certificate verification, only meant for lab environments",
 					Type:        schema.TypeBool,
 					Optional:    true,
 					Default:     false,
 				},
+				"tls_min_version": {
+					Description:      "Minimum TLS version accepted: 1.0, 1.1, 1.2 or 1.3",
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "1.2",
+					ValidateDiagFunc: validateTLSVersion,
+				},
+				"dial_timeout": {
+					Description: "Timeout in seconds to open a connection to a Clickhouse server",
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     30,
+				},
+				"read_timeout": {
+					Description: "Timeout in seconds to read the response of a query, it must be longer than the slowest expected DDL",
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     300,
+				},
+				"max_execution_time": {
+					Description: "Clickhouse max_execution_time setting, maximum query execution time in seconds",
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     30,
+				},
+				"distributed_ddl_task_timeout": {
+					Description: "Clickhouse distributed_ddl_task_timeout setting, seconds to wait for ON CLUSTER statements to be run on every host. The server default is used when not provided",
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     0,
+				},
+				"max_retries": {
+					Description: "Maximum number of retries of a query failing with a transient error, like a network failure or too many simultaneous queries. DDL statements are only retried when the server refused them, as they may have been applied before a network failure or a timeout, unless they are idempotent like `CREATE ... IF NOT EXISTS`, `CREATE OR REPLACE` or `DROP ... IF EXISTS`. 0 disables retries",
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     3,
+				},
+				"retry_initial_backoff": {
+					Description: "Seconds to wait before the first retry, the wait doubles on every retry with some random jitter",
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     1,
+				},
+				"retry_max_backoff": {
+					Description: "Maximum seconds to wait between retries",
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     30,
+				},
+				"settings": {
+					Description: "Additional Clickhouse settings sent with every query, e.g. `{ insert_quorum = \"2\" }`",
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"protocol": {
+					Description:      "Protocol used to connect to Clickhouse: `native` (TCP) or `http`, HTTPS is used when `secure` is enabled",
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "native",
+					ValidateDiagFunc: validateProtocol,
+				},
+				"http_path": {
+					Description: "URL path of the HTTP interface, e.g. when Clickhouse is exposed behind a proxy under `/clickhouse`. Only used with the http protocol",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+				},
+				"http_headers": {
+					Description: "Additional headers sent on every HTTP request, e.g. the ones required by a load balancer. Only used with the http protocol",
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"sql_output_path": {
+					Description: "Path of a file the DDL statements are appended to instead of being run, so they can be reviewed before an apply. Passwords are redacted, and every resource with statements written fails so the state is left unchanged",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "",
+				},
 			},
 			DataSourcesMap: map[string]*schema.Resource{
 				"clickhouse_dbs": datasources.DataSourceDbs(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"clickhouse_db":    resourcedb.ResourceDb(),
-				"clickhouse_table": resourcetable.ResourceTable(),
-				"clickhouse_role":  resourcerole.ResourceRole(),
-				"clickhouse_user":  resourceuser.ResourceUser(),
+				"clickhouse_db":                resourcedb.ResourceDb(),
+				"clickhouse_table":             resourcetable.ResourceTable(),
+				"clickhouse_role":              resourcerole.ResourceRole(),
+				"clickhouse_user":              resourceuser.ResourceUser(),
+				"clickhouse_view":              resourceview.ResourceView(),
+				"clickhouse_materialized_view": resourcematerializedview.ResourceMaterializedView(),
+				"clickhouse_dictionary":        resourcedictionary.ResourceDictionary(),
+				"clickhouse_function":          resourcefunction.ResourceFunction(),
+				"clickhouse_grant":             resourcegrant.ResourceGrant(),
 			},
 			ConfigureContextFunc: configure(),
 		}
+		for _, resource := range p.ResourcesMap {
+			common.WithSQLOutput(resource)
+		}
 
 		return p
 	}
@@ -114,6 +265,50 @@ func getEnvVar(envVarName string) (any, error) {
 
 }
 
+var protocols = map[string]clickhouse.Protocol{
+	"native": clickhouse.Native,
+	"http":   clickhouse.HTTP,
+}
+
+func validateProtocol(inValue any, p cty.Path) diag.Diagnostics {
+	value := inValue.(string)
+	var diags diag.Diagnostics
+	if _, ok := protocols[value]; !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   fmt.Sprintf("%q is not one of native, http", value),
+		})
+	}
+	return diags
+}
+
+func validateTLSVersion(inValue any, p cty.Path) diag.Diagnostics {
+	value := inValue.(string)
+	var diags diag.Diagnostics
+	if _, ok := tlsVersions[value]; !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   fmt.Sprintf("%q is not one of 1.0, 1.1, 1.2, 1.3", value),
+		})
+	}
+	return diags
+}
+
+func validateConnectionOpenStrategy(inValue any, p cty.Path) diag.Diagnostics {
+	value := inValue.(string)
+	var diags diag.Diagnostics
+	if _, ok := connectionOpenStrategies[value]; !ok {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   fmt.Sprintf("%q is not one of in_order, round_robin, random", value),
+		})
+	}
+	return diags
+}
+
 func configure() func(context.Context, *schema.ResourceData) (any, diag.Diagnostics) {
 	return func(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
 		host := d.Get("host").(string)
@@ -122,25 +317,60 @@ func configure() func(context.Context, *schema.ResourceData) (any, diag.Diagnost
 		defaultCluster := d.Get("default_cluster").(string)
 		password := d.Get("password").(string)
 		secure := d.Get("secure").(bool)
+		protocol := protocols[d.Get("protocol").(string)]
+
+		httpHeaders := make(map[string]string)
+		for key, value := range d.Get("http_headers").(map[string]interface{}) {
+			httpHeaders[key] = value.(string)
+		}
 
 		var TLSConfig *tls.Config
 		// To use TLS it's necessary to set the TLSConfig field as not nil
 		if secure {
-			TLSConfig = &tls.Config{
-				InsecureSkipVerify: false,
+			var err error
+			TLSConfig, err = buildTLSConfig(tlsSettings{
+				CACert:             d.Get("ca_cert").(string),
+				ClientCert:         d.Get("client_cert").(string),
+				ClientKey:          d.Get("client_key").(string),
+				ServerName:         d.Get("server_name").(string),
+				InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+				MinVersion:         d.Get("tls_min_version").(string),
+			})
+			if err != nil {
+				return nil, diag.FromErr(fmt.Errorf("configuring TLS: %v", err))
+			}
+		}
+		strategy := d.Get("connection_open_strategy").(string)
+		additionalHosts := common.MapArrayInterfaceToArrayOfStrings(d.Get("additional_hosts").([]interface{}))
+
+		settings := clickhouse.Settings{}
+		for key, value := range d.Get("settings").(map[string]interface{}) {
+			if key == "max_execution_time" || key == "distributed_ddl_task_timeout" {
+				return nil, diag.FromErr(fmt.Errorf("%s must be set with its own provider attribute instead of settings", key))
 			}
+			settings[key] = value.(string)
+		}
+		settings["max_execution_time"] = d.Get("max_execution_time").(int)
+		if ddlTaskTimeout := d.Get("distributed_ddl_task_timeout").(int); ddlTaskTimeout > 0 {
+			settings["distributed_ddl_task_timeout"] = ddlTaskTimeout
 		}
-		conn, err := clickhouse.Open(&clickhouse.Options{
-			Addr: []string{fmt.Sprintf("%s:%d", host, port)},
+
+		options := clickhouse.Options{
+			Addr:             buildAddresses(host, additionalHosts, port, strategy),
+			ConnOpenStrategy: connectionOpenStrategies[strategy],
 			Auth: clickhouse.Auth{
 				Username: username,
 				Password: password,
 			},
-			Settings: clickhouse.Settings{
-				"max_execution_time": 30,
-			},
-			TLS: TLSConfig,
-		})
+			Settings:    settings,
+			DialTimeout: time.Duration(d.Get("dial_timeout").(int)) * time.Second,
+			ReadTimeout: time.Duration(d.Get("read_timeout").(int)) * time.Second,
+			TLS:         TLSConfig,
+			Protocol:    protocol,
+			HttpUrlPath: d.Get("http_path").(string),
+			HttpHeaders: httpHeaders,
+		}
+		conn, err := clickhouse.Open(&options)
 
 		var diags diag.Diagnostics
 
@@ -148,10 +378,38 @@ func configure() func(context.Context, *schema.ResourceData) (any, diag.Diagnost
 			return nil, diag.FromErr(fmt.Errorf("error connecting to clickhouse: %v", err))
 		}
 
+		if ddlHost := d.Get("ddl_host").(string); ddlHost != "" {
+			ddlOptions := options
+			ddlOptions.Addr = []string{buildAddress(ddlHost, port)}
+			ddlConn, err := clickhouse.Open(&ddlOptions)
+			if err != nil {
+				return nil, diag.FromErr(fmt.Errorf("error connecting to clickhouse ddl host: %v", err))
+			}
+			conn = &ddlRoutedConn{Conn: conn, ddl: ddlConn}
+		}
+
+		maxRetries := d.Get("max_retries").(int)
+		if maxRetries < 0 {
+			return nil, diag.FromErr(fmt.Errorf("max_retries must not be negative"))
+		}
+		conn = &common.RetryConn{
+			Conn: conn,
+			Policy: common.RetryPolicy{
+				MaxRetries:     maxRetries,
+				InitialBackoff: time.Duration(d.Get("retry_initial_backoff").(int)) * time.Second,
+				MaxBackoff:     time.Duration(d.Get("retry_max_backoff").(int)) * time.Second,
+			},
+		}
+
 		if err := conn.Ping(ctx); err != nil {
 			return nil, diag.FromErr(fmt.Errorf("ping clickhouse database: %w", err))
 		}
 
-		return &common.ApiClient{ClickhouseConnection: &conn, DefaultCluster: defaultCluster}, diags
+		var executor common.Executor = conn
+		if sqlOutputPath := d.Get("sql_output_path").(string); sqlOutputPath != "" {
+			executor = &common.SQLOutputExecutor{Executor: conn, Path: sqlOutputPath}
+		}
+
+		return &common.ApiClient{ClickhouseConnection: executor, DefaultCluster: defaultCluster}, diags
 	}
 }
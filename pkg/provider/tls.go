@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type tlsSettings struct {
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	ServerName         string
+	InsecureSkipVerify bool
+	MinVersion         string
+}
+
+// readPEM returns the PEM content of a certificate or key given either inline or as a file path.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	content, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", value, err)
+	}
+	return content, nil
+}
+
+func buildTLSConfig(settings tlsSettings) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[settings.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("%q is not a supported TLS version", settings.MinVersion)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         settings.ServerName,
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+		MinVersion:         minVersion,
+	}
+
+	if settings.CACert != "" {
+		caCert, err := readPEM(settings.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("ca_cert: %v", err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("ca_cert: no valid PEM certificate found")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if (settings.ClientCert == "") != (settings.ClientKey == "") {
+		return nil, fmt.Errorf("client_cert and client_key must be provided together")
+	}
+	if settings.ClientCert != "" {
+		clientCert, err := readPEM(settings.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("client_cert: %v", err)
+		}
+		clientKey, err := readPEM(settings.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client_key: %v", err)
+		}
+		certificate, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
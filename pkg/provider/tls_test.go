@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func generateCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "clickhouse.test"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshalling key: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	certPEM, keyPEM := generateCertificate(t)
+	certFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(certFile, []byte(certPEM), 0600); err != nil {
+		t.Fatalf("writing certificate: %v", err)
+	}
+
+	tlsConfig, err := buildTLSConfig(tlsSettings{
+		CACert:     certFile,
+		ClientCert: certPEM,
+		ClientKey:  keyPEM,
+		ServerName: "clickhouse.test",
+		MinVersion: "1.3",
+	})
+	if err != nil {
+		t.Fatalf("buildTLSConfig failed: %v", err)
+	}
+	if tlsConfig.RootCAs == nil || len(tlsConfig.Certificates) != 1 {
+		t.Errorf("expected CA bundle and client certificate to be loaded")
+	}
+	if tlsConfig.ServerName != "clickhouse.test" || tlsConfig.MinVersion != tls.VersionTLS13 || tlsConfig.InsecureSkipVerify {
+		t.Errorf("unexpected TLS config %+v", tlsConfig)
+	}
+
+	wrongSettings := []tlsSettings{
+		{MinVersion: "1.2", ClientCert: certPEM},
+		{MinVersion: "1.2", CACert: "-----BEGIN CERTIFICATE-----\nnot a certificate\n-----END CERTIFICATE-----"},
+		{MinVersion: "1.2", CACert: filepath.Join(t.TempDir(), "missing.pem")},
+		{MinVersion: "2.0"},
+	}
+	for _, settings := range wrongSettings {
+		if _, err := buildTLSConfig(settings); err == nil {
+			t.Errorf("buildTLSConfig(%+v) expected to fail", settings)
+		}
+	}
+}
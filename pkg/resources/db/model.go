@@ -1,7 +1,15 @@
 package resourcedb
 
-import "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+import (
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/dictionary"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/materializedview"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/view"
+)
 
 type CHDBResources struct {
-	CHTables []resourcetable.CHTable
+	CHTables            []resourcetable.CHTable
+	CHViews             []resourceview.CHView
+	CHMaterializedViews []resourcematerializedview.CHMaterializedView
+	CHDictionaries      []resourcedictionary.CHDictionary
 }
@@ -2,10 +2,16 @@ package resourcedb
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	resourcetable "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+	"strings"
 
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	resourcedictionary "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/dictionary"
+	resourcematerializedview "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/materializedview"
+	resourcetable "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+	resourceview "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/view"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -19,6 +25,13 @@ func ResourceDb() *schema.Resource {
 		CreateContext: resourceDbCreate,
 		ReadContext:   resourceDbRead,
 		DeleteContext: resourceDbDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDbImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"cluster": &schema.Schema{
@@ -67,11 +80,11 @@ func ResourceDb() *schema.Resource {
 func resourceDbRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	client := meta.(*common.ApiClient)
 	var diags diag.Diagnostics
-	conn := *client.ClickhouseConnection
+	conn := client.ClickhouseConnection
 	defaultCluster := client.DefaultCluster
 
 	database_name := d.Get("name").(string)
-	row := conn.QueryRow(ctx, fmt.Sprintf("SELECT name, engine, data_path, metadata_path, uuid, comment FROM system.databases where name = '%v'", database_name))
+	row := conn.QueryRow(ctx, "SELECT name, engine, data_path, metadata_path, uuid, comment FROM system.databases where name = ?", database_name)
 
 	if row.Err() != nil {
 		return diag.FromErr(fmt.Errorf("reading database from Clickhouse: %v", row.Err()))
@@ -80,27 +93,27 @@ func resourceDbRead(ctx context.Context, d *schema.ResourceData, meta any) diag.
 	var name, engine, dataPath, metadataPath, uuid, storedComment string
 
 	err := row.Scan(&name, &engine, &dataPath, &metadataPath, &uuid, &storedComment)
+	if errors.Is(err, sql.ErrNoRows) {
+		tflog.Warn(ctx, "Database not found, removing it from state", map[string]interface{}{"database": database_name})
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("scanning Clickhouse DB row: %v", err))
 	}
 
-	if name == "" {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  fmt.Sprintf("Database %v not found", database_name),
-			Detail:   "Not possible to retrieve db from server. Could you be performing operation in a cluster? If so try configuring default cluster name on you provider configuration.",
-		})
-		return diags
-	}
-
+	// Legacy comments are not rewritten for databases, their comment can't be changed without replacing them
 	comment, cluster, err := common.UnmarshalComment(storedComment)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Warning,
 			Summary:  fmt.Sprintf("Unable to unmarshal comments for db %q", name),
-			Detail:   "Unable to unmarshal comments in order to retrieve cluster information for the table, so that default cluster is going to be used instead.",
+			Detail:   "Unable to unmarshal comments in order to retrieve cluster information for the table, so that the imported or default cluster is going to be used instead.",
 		})
-		comment, cluster = storedComment, defaultCluster
+		comment, cluster = storedComment, d.Get("cluster").(string)
+		if cluster == "" {
+			cluster = defaultCluster
+		}
 	}
 
 	err = d.Set("name", name)
@@ -160,20 +173,36 @@ func resourceDbRead(ctx context.Context, d *schema.ResourceData, meta any) diag.
 	return diags
 }
 
+func resourceDbImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Same ID format written by resourceDbCreate, the cluster part may be empty: <cluster>:<database>
+	separator := strings.LastIndex(d.Id(), ":")
+	if separator == -1 || separator == len(d.Id())-1 {
+		return nil, fmt.Errorf("unexpected import id %q, expected <cluster>:<database>", d.Id())
+	}
+
+	if err := d.Set("cluster", d.Id()[:separator]); err != nil {
+		return nil, fmt.Errorf("setting cluster: %v", err)
+	}
+	if err := d.Set("name", d.Id()[separator+1:]); err != nil {
+		return nil, fmt.Errorf("setting name: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceDbCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	client := meta.(*common.ApiClient)
 	var diags diag.Diagnostics
-	conn := *client.ClickhouseConnection
+	conn := client.ClickhouseConnection
 
 	cluster, _ := d.Get("cluster").(string)
 	if cluster == "" {
 		cluster = client.DefaultCluster
 	}
-	clusterStatement := common.GetClusterStatement(cluster)
 	databaseName := d.Get("name").(string)
 	comment := d.Get("comment").(string)
 
-	query := fmt.Sprintf("CREATE DATABASE %v %v COMMENT '%v'", databaseName, clusterStatement, common.GetComment(comment, cluster))
+	query := buildCreateDatabaseQuery(databaseName, cluster, comment)
 
 	err := conn.Exec(ctx, query)
 	if err != nil {
@@ -203,21 +232,36 @@ func resourceDbDelete(ctx context.Context, d *schema.ResourceData, meta any) dia
 	}
 
 	chTableService := resourcetable.CHTableService{CHConnection: conn}
-	chDBService := CHDBService{CHConnection: conn, CHTableService: &chTableService}
+	chViewService := resourceview.CHViewService{CHConnection: conn}
+	chMaterializedViewService := resourcematerializedview.CHMaterializedViewService{CHConnection: conn}
+	chDictionaryService := resourcedictionary.CHDictionaryService{CHConnection: conn}
+	chDBService := CHDBService{CHConnection: conn, CHTableService: &chTableService, CHViewService: &chViewService, CHMaterializedViewService: &chMaterializedViewService, CHDictionaryService: &chDictionaryService}
 	dbResources, err := chDBService.GetDBResources(ctx, databaseName)
 
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("resource db delete: %v", err))
 	}
-	if len(dbResources.CHTables) > 0 {
+	if len(dbResources.CHTables) > 0 || len(dbResources.CHViews) > 0 || len(dbResources.CHMaterializedViews) > 0 || len(dbResources.CHDictionaries) > 0 {
 		var tableNames []string
 		for _, table := range dbResources.CHTables {
 			tableNames = append(tableNames, table.Name)
 		}
+		var viewNames []string
+		for _, view := range dbResources.CHViews {
+			viewNames = append(viewNames, view.Name)
+		}
+		var materializedViewNames []string
+		for _, view := range dbResources.CHMaterializedViews {
+			materializedViewNames = append(materializedViewNames, view.Name)
+		}
+		var dictionaryNames []string
+		for _, dictionary := range dbResources.CHDictionaries {
+			dictionaryNames = append(dictionaryNames, dictionary.Name)
+		}
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  fmt.Sprintf("Unable to delete db resource %q", databaseName),
-			Detail:   fmt.Sprintf("DB resource is used by another resources and is not possible to delete it. Tables: %v.", tableNames),
+			Detail:   fmt.Sprintf("DB resource is used by another resources and is not possible to delete it. Tables: %v. Views: %v. Materialized views: %v. Dictionaries: %v.", tableNames, viewNames, materializedViewNames, dictionaryNames),
 		})
 		return diags
 	}
@@ -226,11 +270,10 @@ func resourceDbDelete(ctx context.Context, d *schema.ResourceData, meta any) dia
 	if cluster == "" {
 		cluster = client.DefaultCluster
 	}
-	clusterStatement := common.GetClusterStatement(cluster)
 
-	query := fmt.Sprintf("DROP DATABASE %v %v SYNC", databaseName, clusterStatement)
+	query := buildDropDatabaseQuery(databaseName, cluster)
 
-	err = (*conn).Exec(ctx, query)
+	err = conn.Exec(ctx, query)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -47,6 +47,18 @@ func TestAccResourceDb(t *testing.T) {
 						"clickhouse_db.new_db", "comment", regexp.MustCompile("^"+testResourceDBDatabaseComment2)),
 				),
 			},
+			{
+				ResourceName:      "clickhouse_db.new_db",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// DATABASE DROPPED OUTSIDE TERRAFORM IS RECREATED
+			{
+				PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("DROP DATABASE %s", testResourceDBDatabaseName2)),
+				Config:             dbConfig(testResourceDBDatabaseName2, testResourceDBDatabaseComment2),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
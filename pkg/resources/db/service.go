@@ -3,13 +3,19 @@ package resourcedb
 import (
 	"context"
 	"fmt"
-	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/dictionary"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/materializedview"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/view"
 )
 
 type CHDBService struct {
-	CHConnection   *driver.Conn
-	CHTableService *resourcetable.CHTableService
+	CHConnection              common.Executor
+	CHTableService            *resourcetable.CHTableService
+	CHViewService             *resourceview.CHViewService
+	CHMaterializedViewService *resourcematerializedview.CHMaterializedViewService
+	CHDictionaryService       *resourcedictionary.CHDictionaryService
 }
 
 func (ts *CHDBService) GetDBResources(ctx context.Context, database string) (*CHDBResources, error) {
@@ -21,5 +27,28 @@ func (ts *CHDBService) GetDBResources(ctx context.Context, database string) (*CH
 		return nil, fmt.Errorf("error getting tables from database: %v", err)
 	}
 
+	dbResources.CHViews, err = ts.CHViewService.GetDBViews(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("error getting views from database: %v", err)
+	}
+
+	dbResources.CHMaterializedViews, err = ts.CHMaterializedViewService.GetDBMaterializedViews(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("error getting materialized views from database: %v", err)
+	}
+
+	dbResources.CHDictionaries, err = ts.CHDictionaryService.GetDBDictionaries(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("error getting dictionaries from database: %v", err)
+	}
+
 	return &dbResources, nil
 }
+
+func buildCreateDatabaseQuery(name string, cluster string, comment string) string {
+	return fmt.Sprintf("CREATE DATABASE %v %v COMMENT %v", common.QuoteIdentifier(name), common.GetClusterStatement(cluster), common.QuoteString(common.GetComment(comment, cluster)))
+}
+
+func buildDropDatabaseQuery(name string, cluster string) string {
+	return fmt.Sprintf("DROP DATABASE %v %v SYNC", common.QuoteIdentifier(name), common.GetClusterStatement(cluster))
+}
@@ -0,0 +1,59 @@
+package resourcedb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	resourcedictionary "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/dictionary"
+	resourcematerializedview "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/materializedview"
+	resourcetable "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+	resourceview "github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/view"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+)
+
+func TestGetDBResources(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.tables",
+		chfake.Row{"database": "db", "name": "events", "engine": "MergeTree"},
+		chfake.Row{"database": "db", "name": "daily_events", "engine": "View"},
+		chfake.Row{"database": "db", "name": "events_mv", "engine": "MaterializedView"},
+		chfake.Row{"database": "db", "name": "users_dict", "engine": "Dictionary"},
+		chfake.Row{"database": "other", "name": "users", "engine": "MergeTree"},
+	)
+	fake.AddRows("system.dictionaries", chfake.Row{"database": "db", "name": "users_dict"})
+	service := CHDBService{
+		CHConnection:              fake,
+		CHTableService:            &resourcetable.CHTableService{CHConnection: fake},
+		CHViewService:             &resourceview.CHViewService{CHConnection: fake},
+		CHMaterializedViewService: &resourcematerializedview.CHMaterializedViewService{CHConnection: fake},
+		CHDictionaryService:       &resourcedictionary.CHDictionaryService{CHConnection: fake},
+	}
+
+	resources, err := service.GetDBResources(context.Background(), "db")
+	if err != nil {
+		t.Fatalf("GetDBResources failed: %v", err)
+	}
+	if len(resources.CHTables) != 1 || resources.CHTables[0].Name != "events" || len(resources.CHViews) != 1 || resources.CHViews[0].Name != "daily_events" ||
+		len(resources.CHMaterializedViews) != 1 || resources.CHMaterializedViews[0].Name != "events_mv" ||
+		len(resources.CHDictionaries) != 1 || resources.CHDictionaries[0].Name != "users_dict" {
+		t.Errorf("unexpected database resources %+v", resources)
+	}
+
+	resources, err = service.GetDBResources(context.Background(), "empty")
+	if err != nil || len(resources.CHTables) != 0 || len(resources.CHViews) != 0 || len(resources.CHMaterializedViews) != 0 || len(resources.CHDictionaries) != 0 {
+		t.Errorf("GetDBResources() of an empty database = %+v, %v", resources, err)
+	}
+}
+
+func TestDatabaseQueries(t *testing.T) {
+	statements := []string{
+		buildCreateDatabaseQuery("db", "", ""),
+		buildCreateDatabaseQuery("analytics", "cluster", "it's the analytics database"),
+		buildCreateDatabaseQuery("db", "'{cluster}'", ""),
+		buildDropDatabaseQuery("db", ""),
+		buildDropDatabaseQuery("analytics", "cluster"),
+	}
+	golden.Assert(t, "database_queries", strings.Join(statements, "\n"))
+}
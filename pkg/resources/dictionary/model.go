@@ -0,0 +1,290 @@
+package resourcedictionary
+
+import (
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+// layoutTypes maps the layout type names reported by system.dictionaries to the ones used in the DDL,
+// layouts missing here are taken from the state.
+var layoutTypes = map[string]string{
+	"Flat":                   "flat",
+	"Hashed":                 "hashed",
+	"SparseHashed":           "sparse_hashed",
+	"HashedArray":            "hashed_array",
+	"ComplexKeyHashed":       "complex_key_hashed",
+	"ComplexKeySparseHashed": "complex_key_sparse_hashed",
+	"ComplexKeyHashedArray":  "complex_key_hashed_array",
+	"RangeHashed":            "range_hashed",
+	"ComplexKeyRangeHashed":  "complex_key_range_hashed",
+	"Cache":                  "cache",
+	"ComplexKeyCache":        "complex_key_cache",
+	"SSDCache":               "ssd_cache",
+	"ComplexKeySSDCache":     "complex_key_ssd_cache",
+	"Direct":                 "direct",
+	"ComplexKeyDirect":       "complex_key_direct",
+	"Trie":                   "ip_trie",
+}
+
+// SourceTypes are the dictionary sources supported by Clickhouse.
+var SourceTypes = []string{
+	"clickhouse",
+	"http",
+	"file",
+	"executable",
+	"executable_pool",
+	"mysql",
+	"postgresql",
+	"mongodb",
+	"redis",
+	"cassandra",
+	"odbc",
+	"jdbc",
+	"null",
+	"yamlregexptree",
+}
+
+// LayoutTypes are the dictionary layouts supported by Clickhouse.
+var LayoutTypes = []string{
+	"flat",
+	"hashed",
+	"sparse_hashed",
+	"hashed_array",
+	"complex_key_hashed",
+	"complex_key_sparse_hashed",
+	"complex_key_hashed_array",
+	"range_hashed",
+	"complex_key_range_hashed",
+	"cache",
+	"complex_key_cache",
+	"ssd_cache",
+	"complex_key_ssd_cache",
+	"direct",
+	"complex_key_direct",
+	"ip_trie",
+	"polygon",
+	"polygon_index_each_row",
+	"polygon_index_cell",
+	"regexp_tree",
+}
+
+type CHDictionary struct {
+	Database       string   `ch:"database"`
+	Name           string   `ch:"name"`
+	Status         string   `ch:"status"`
+	Type           string   `ch:"type"`
+	KeyNames       []string `ch:"key.names"`
+	AttributeNames []string `ch:"attribute.names"`
+	AttributeTypes []string `ch:"attribute.types"`
+	LifetimeMin    uint64   `ch:"lifetime_min"`
+	LifetimeMax    uint64   `ch:"lifetime_max"`
+	Comment        string   `ch:"comment"`
+	// CreateTableQuery is the definition of the dictionary listed in system.tables
+	CreateTableQuery string `ch:"create_table_query"`
+}
+
+type AttributeResource struct {
+	Name         string
+	Type         string
+	Default      string
+	Expression   string
+	Hierarchical bool
+	Injective    bool
+}
+
+type SourceResource struct {
+	Type       string
+	Parameters map[string]string
+	Password   string
+}
+
+type LayoutResource struct {
+	Type       string
+	Parameters map[string]string
+}
+
+type LifetimeResource struct {
+	Min uint64
+	Max uint64
+}
+
+type RangeResource struct {
+	Min string
+	Max string
+}
+
+type DictionaryResource struct {
+	Database   string
+	Name       string
+	Cluster    string
+	PrimaryKey []string
+	Attributes []AttributeResource
+	Source     SourceResource
+	Layout     LayoutResource
+	Lifetime   *LifetimeResource
+	Range      *RangeResource
+	Comment    string
+}
+
+// ToResource returns the definition of the dictionary known by Clickhouse, read from its create_table_query.
+// When it isn't available, the source and the range are left empty for KeepState to take them from the state,
+// as they aren't reported by system.dictionaries, and so are the layout and lifetime until the dictionary is loaded.
+func (d *CHDictionary) ToResource() *DictionaryResource {
+	comment, cluster := common.DecodeComment(d.Comment)
+
+	if definition := parseCreateDictionaryQuery(d.CreateTableQuery); definition != nil {
+		definition.Database = d.Database
+		definition.Name = d.Name
+		definition.Cluster = cluster
+		definition.Comment = comment
+		return definition
+	}
+
+	dictionaryResource := DictionaryResource{
+		Database:   d.Database,
+		Name:       d.Name,
+		Cluster:    cluster,
+		PrimaryKey: d.KeyNames,
+		Layout:     LayoutResource{Type: layoutTypes[d.Type]},
+		Comment:    comment,
+	}
+	for i, name := range d.AttributeNames {
+		attribute := AttributeResource{Name: name}
+		if i < len(d.AttributeTypes) {
+			attribute.Type = d.AttributeTypes[i]
+		}
+		dictionaryResource.Attributes = append(dictionaryResource.Attributes, attribute)
+	}
+	if d.LifetimeMin != 0 || d.LifetimeMax != 0 {
+		dictionaryResource.Lifetime = &LifetimeResource{Min: d.LifetimeMin, Max: d.LifetimeMax}
+	}
+	return &dictionaryResource
+}
+
+// KeepState keeps the parts of the state Clickhouse still agrees with, so only actual changes are reported as
+// drift, and fills the ones it doesn't report, like the source password, with the state ones.
+func (d *DictionaryResource) KeepState(state *DictionaryResource) {
+	if d.Source.Type == "" {
+		// Read from system.dictionaries only
+		d.keepUnreportedState(state)
+		return
+	}
+
+	if sourcesEquivalent(d.Source, state.Source) {
+		d.Source = state.Source
+	} else {
+		// Clickhouse hides the password
+		d.Source.Password = state.Source.Password
+	}
+	if strings.EqualFold(d.Layout.Type, state.Layout.Type) && parametersEquivalent(d.Layout.Parameters, state.Layout.Parameters) {
+		d.Layout = state.Layout
+	}
+	if d.Lifetime == nil && state.Lifetime != nil && state.Lifetime.Min == 0 && state.Lifetime.Max == 0 {
+		// LIFETIME(0) may not be written back
+		d.Lifetime = state.Lifetime
+	}
+
+	for i, attribute := range d.Attributes {
+		for _, stateAttribute := range state.Attributes {
+			if stateAttribute.Name != attribute.Name {
+				continue
+			}
+			if strings.EqualFold(stateAttribute.Type, attribute.Type) {
+				d.Attributes[i].Type = stateAttribute.Type
+			}
+			if common.QueriesEquivalent(stateAttribute.Default, attribute.Default, d.Database) {
+				d.Attributes[i].Default = stateAttribute.Default
+			}
+			if common.QueriesEquivalent(stateAttribute.Expression, attribute.Expression, d.Database) {
+				d.Attributes[i].Expression = stateAttribute.Expression
+			}
+		}
+	}
+}
+
+// keepUnreportedState fills the parts of the definition not reported by system.dictionaries with the state ones,
+// and keeps the settings of the state attributes and layout Clickhouse still agrees with.
+func (d *DictionaryResource) keepUnreportedState(state *DictionaryResource) {
+	d.Source = state.Source
+	d.Range = state.Range
+
+	if d.Layout.Type == "" {
+		// Not loaded yet, the layout and lifetime are unknown
+		d.Layout = state.Layout
+		d.Lifetime = state.Lifetime
+	} else if strings.EqualFold(d.Layout.Type, state.Layout.Type) {
+		d.Layout = state.Layout
+	}
+	if d.Lifetime == nil && state.Lifetime != nil && state.Lifetime.Min == 0 && state.Lifetime.Max == 0 {
+		// LIFETIME(0) is reported as no lifetime
+		d.Lifetime = state.Lifetime
+	}
+
+	if len(d.PrimaryKey) == 0 {
+		d.PrimaryKey = state.PrimaryKey
+	}
+
+	// The key and range columns are declared along with the attributes but system.dictionaries reports them
+	// apart, without their types
+	structureColumns := make(map[string]bool)
+	for _, name := range state.PrimaryKey {
+		structureColumns[name] = true
+	}
+	if state.Range != nil {
+		structureColumns[state.Range.Min] = true
+		structureColumns[state.Range.Max] = true
+	}
+	var attributes []AttributeResource
+	var stateAttributes []AttributeResource
+	for _, stateAttribute := range state.Attributes {
+		if structureColumns[stateAttribute.Name] {
+			attributes = append(attributes, stateAttribute)
+		} else {
+			stateAttributes = append(stateAttributes, stateAttribute)
+		}
+	}
+	if len(d.Attributes) == 0 || attributesEqual(d.Attributes, stateAttributes) {
+		d.Attributes = state.Attributes
+		return
+	}
+	for _, attribute := range d.Attributes {
+		for _, stateAttribute := range stateAttributes {
+			if stateAttribute.Name == attribute.Name && stateAttribute.Type == attribute.Type {
+				attribute = stateAttribute
+			}
+		}
+		attributes = append(attributes, attribute)
+	}
+	d.Attributes = attributes
+}
+
+// sourcesEquivalent compares a source read from Clickhouse, without password, with the state one. Parameter
+// names are case insensitive.
+func sourcesEquivalent(read SourceResource, state SourceResource) bool {
+	return strings.EqualFold(read.Type, state.Type) && parametersEquivalent(read.Parameters, state.Parameters)
+}
+
+func parametersEquivalent(read map[string]string, state map[string]string) bool {
+	if len(read) != len(state) {
+		return false
+	}
+	for key, value := range state {
+		if readValue, ok := read[strings.ToLower(key)]; !ok || readValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+func attributesEqual(a []AttributeResource, b []AttributeResource) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Type != b[i].Type {
+			return false
+		}
+	}
+	return true
+}
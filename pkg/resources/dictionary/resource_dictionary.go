@@ -0,0 +1,414 @@
+package resourcedictionary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceDictionary() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage dictionaries created with DDL",
+
+		CreateContext: resourceDictionaryCreate,
+		ReadContext:   resourceDictionaryRead,
+		UpdateContext: resourceDictionaryUpdate,
+		DeleteContext: resourceDictionaryDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDictionaryImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"database": {
+				Description: "DB Name where the dictionary belongs",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Description: "Dictionary name",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"cluster": {
+				Description: "Cluster name, the provider default cluster is used when not provided",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"attribute": {
+				Description: "Dictionary columns, including the primary key and range ones",
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Attribute name",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"type": {
+							Description: "Attribute type, e.g. `UInt64` or `Nullable(String)`",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"default": {
+							Description: "Default value expression for the keys missing in the source, e.g. `''`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"expression": {
+							Description: "Expression Clickhouse sends to the source to compute the attribute",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"hierarchical": {
+							Description: "Whether the attribute holds the parent key of a hierarchy",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+						"injective": {
+							Description: "Whether the key to attribute mapping is injective, allowing GROUP BY optimizations",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+					},
+				},
+			},
+			"primary_key": {
+				Description: "Key attributes, more than one requires a `complex_key_*` layout",
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"source": {
+				Description: "Source the dictionary is loaded from",
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description:      "Source type: " + strings.Join(SourceTypes, ", "),
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: ValidateSourceType,
+						},
+						"parameters": {
+							Description: "Source parameters, e.g. `{ db = \"default\", table = \"users\" }` or `{ url = \"https://example.com/users.csv\", format = \"CSVWithNames\" }`",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"password": {
+							Description: "Password of the source, kept apart from the parameters so it isn't shown",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+						},
+					},
+				},
+			},
+			"layout": {
+				Description: "How the dictionary is stored in memory",
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description:      "Layout type: " + strings.Join(LayoutTypes, ", "),
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: ValidateLayoutType,
+						},
+						"parameters": {
+							Description: "Layout parameters, e.g. `{ size_in_cells = \"1000000\" }` for the cache layout",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"lifetime": {
+				Description: "Range in seconds Clickhouse picks the reload interval from, no reloads when `max` is 0",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min": {
+							Description: "Minimum seconds between reloads",
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     0,
+						},
+						"max": {
+							Description: "Maximum seconds between reloads",
+							Type:        schema.TypeInt,
+							Required:    true,
+						},
+					},
+				},
+			},
+			"range": {
+				Description: "Attributes holding the validity range of the rows, for the `range_hashed` layouts",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min": {
+							Description: "Attribute holding the start of the range",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"max": {
+							Description: "Attribute holding the end of the range",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+					},
+				},
+			},
+			"comment": {
+				Description: "Dictionary comment, it will be codified in a json along with some metadata information (like cluster name in case of clustering)",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+		},
+	}
+}
+
+func getDictionaryResource(d *schema.ResourceData, defaultCluster string) DictionaryResource {
+	dictionaryResource := DictionaryResource{
+		Database:   d.Get("database").(string),
+		Name:       d.Get("name").(string),
+		Cluster:    d.Get("cluster").(string),
+		PrimaryKey: common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{})),
+		Comment:    d.Get("comment").(string),
+	}
+	if dictionaryResource.Cluster == "" {
+		dictionaryResource.Cluster = defaultCluster
+	}
+
+	for _, attribute := range d.Get("attribute").([]interface{}) {
+		attributeMap := attribute.(map[string]interface{})
+		dictionaryResource.Attributes = append(dictionaryResource.Attributes, AttributeResource{
+			Name:         attributeMap["name"].(string),
+			Type:         attributeMap["type"].(string),
+			Default:      attributeMap["default"].(string),
+			Expression:   attributeMap["expression"].(string),
+			Hierarchical: attributeMap["hierarchical"].(bool),
+			Injective:    attributeMap["injective"].(bool),
+		})
+	}
+	for _, source := range d.Get("source").([]interface{}) {
+		sourceMap := source.(map[string]interface{})
+		dictionaryResource.Source = SourceResource{
+			Type:       sourceMap["type"].(string),
+			Parameters: mapInterfaceToMapOfStrings(sourceMap["parameters"].(map[string]interface{})),
+			Password:   sourceMap["password"].(string),
+		}
+	}
+	for _, layout := range d.Get("layout").([]interface{}) {
+		layoutMap := layout.(map[string]interface{})
+		dictionaryResource.Layout = LayoutResource{
+			Type:       layoutMap["type"].(string),
+			Parameters: mapInterfaceToMapOfStrings(layoutMap["parameters"].(map[string]interface{})),
+		}
+	}
+	for _, lifetime := range d.Get("lifetime").([]interface{}) {
+		lifetimeMap := lifetime.(map[string]interface{})
+		dictionaryResource.Lifetime = &LifetimeResource{
+			Min: uint64(lifetimeMap["min"].(int)),
+			Max: uint64(lifetimeMap["max"].(int)),
+		}
+	}
+	for _, dictionaryRange := range d.Get("range").([]interface{}) {
+		rangeMap := dictionaryRange.(map[string]interface{})
+		dictionaryResource.Range = &RangeResource{
+			Min: rangeMap["min"].(string),
+			Max: rangeMap["max"].(string),
+		}
+	}
+	return dictionaryResource
+}
+
+func mapInterfaceToMapOfStrings(in map[string]interface{}) map[string]string {
+	out := make(map[string]string)
+	for key, value := range in {
+		out[key] = value.(string)
+	}
+	return out
+}
+
+func resourceDictionaryRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chDictionaryService := CHDictionaryService{CHConnection: client.ClickhouseConnection}
+
+	database := d.Get("database").(string)
+	dictionaryName := d.Get("name").(string)
+
+	chDictionary, err := chDictionaryService.GetDictionary(ctx, database, dictionaryName)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse dictionary: %v", err))
+	}
+	if chDictionary == nil {
+		tflog.Warn(ctx, "Dictionary not found, removing it from state", map[string]interface{}{"database": database, "dictionary": dictionaryName})
+		d.SetId("")
+		return diags
+	}
+
+	stateDictionary := getDictionaryResource(d, "")
+	dictionaryResource := chDictionary.ToResource()
+	dictionaryResource.KeepState(&stateDictionary)
+	dictionaryResource.Cluster = common.KnownCluster(dictionaryResource.Cluster, d.Get("cluster").(string))
+
+	if err := d.Set("database", dictionaryResource.Database); err != nil {
+		return diag.FromErr(fmt.Errorf("setting database: %v", err))
+	}
+	if err := d.Set("name", dictionaryResource.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %v", err))
+	}
+	if err := d.Set("cluster", dictionaryResource.Cluster); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster: %v", err))
+	}
+	if err := d.Set("primary_key", dictionaryResource.PrimaryKey); err != nil {
+		return diag.FromErr(fmt.Errorf("setting primary_key: %v", err))
+	}
+	if err := d.Set("attribute", flattenAttributes(dictionaryResource.Attributes)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting attribute: %v", err))
+	}
+	source := []map[string]interface{}{{
+		"type":       dictionaryResource.Source.Type,
+		"parameters": dictionaryResource.Source.Parameters,
+		"password":   dictionaryResource.Source.Password,
+	}}
+	if err := d.Set("source", source); err != nil {
+		return diag.FromErr(fmt.Errorf("setting source: %v", err))
+	}
+	if dictionaryResource.Layout.Type != "" {
+		layout := []map[string]interface{}{{"type": dictionaryResource.Layout.Type, "parameters": dictionaryResource.Layout.Parameters}}
+		if err := d.Set("layout", layout); err != nil {
+			return diag.FromErr(fmt.Errorf("setting layout: %v", err))
+		}
+	}
+	var lifetime []map[string]interface{}
+	if dictionaryResource.Lifetime != nil {
+		lifetime = append(lifetime, map[string]interface{}{"min": int(dictionaryResource.Lifetime.Min), "max": int(dictionaryResource.Lifetime.Max)})
+	}
+	if err := d.Set("lifetime", lifetime); err != nil {
+		return diag.FromErr(fmt.Errorf("setting lifetime: %v", err))
+	}
+	var dictionaryRange []map[string]interface{}
+	if dictionaryResource.Range != nil {
+		dictionaryRange = append(dictionaryRange, map[string]interface{}{"min": dictionaryResource.Range.Min, "max": dictionaryResource.Range.Max})
+	}
+	if err := d.Set("range", dictionaryRange); err != nil {
+		return diag.FromErr(fmt.Errorf("setting range: %v", err))
+	}
+	if err := d.Set("comment", dictionaryResource.Comment); err != nil {
+		return diag.FromErr(fmt.Errorf("setting comment: %v", err))
+	}
+
+	d.SetId(dictionaryResource.Cluster + ":" + database + ":" + dictionaryName)
+
+	return diags
+}
+
+func flattenAttributes(attributes []AttributeResource) []map[string]interface{} {
+	var flattened []map[string]interface{}
+	for _, attribute := range attributes {
+		flattened = append(flattened, map[string]interface{}{
+			"name":         attribute.Name,
+			"type":         attribute.Type,
+			"default":      attribute.Default,
+			"expression":   attribute.Expression,
+			"hierarchical": attribute.Hierarchical,
+			"injective":    attribute.Injective,
+		})
+	}
+	return flattened
+}
+
+func resourceDictionaryImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Same ID format written by resourceDictionaryCreate, the cluster part may be empty: <cluster>:<database>:<dictionary>
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) < 3 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return nil, fmt.Errorf("unexpected import id %q, expected <cluster>:<database>:<dictionary>", d.Id())
+	}
+
+	if err := d.Set("cluster", strings.Join(parts[:len(parts)-2], ":")); err != nil {
+		return nil, fmt.Errorf("setting cluster: %v", err)
+	}
+	if err := d.Set("database", parts[len(parts)-2]); err != nil {
+		return nil, fmt.Errorf("setting database: %v", err)
+	}
+	if err := d.Set("name", parts[len(parts)-1]); err != nil {
+		return nil, fmt.Errorf("setting name: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceDictionaryCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chDictionaryService := CHDictionaryService{CHConnection: client.ClickhouseConnection}
+	dictionaryResource := getDictionaryResource(d, client.DefaultCluster)
+
+	if err := chDictionaryService.CreateDictionary(ctx, dictionaryResource); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(dictionaryResource.Cluster + ":" + dictionaryResource.Database + ":" + dictionaryResource.Name)
+
+	return diags
+}
+
+func resourceDictionaryUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chDictionaryService := CHDictionaryService{CHConnection: client.ClickhouseConnection}
+
+	// Dictionaries have no ALTER statements, the whole dictionary is replaced atomically instead
+	if err := chDictionaryService.ReplaceDictionary(ctx, getDictionaryResource(d, client.DefaultCluster)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDictionaryDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chDictionaryService := CHDictionaryService{CHConnection: client.ClickhouseConnection}
+
+	if err := chDictionaryService.DeleteDictionary(ctx, getDictionaryResource(d, client.DefaultCluster)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
@@ -0,0 +1,105 @@
+package resourcedictionary_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testResourceDictionaryDatabaseName = "dictionary_test_database"
+
+func TestAccResourceDictionary(t *testing.T) {
+	const dictionaryName = "users_dict"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: dictionaryConfig(testResourceDictionaryDatabaseName, dictionaryName, "hashed", "300", "users"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "name", dictionaryName),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "primary_key.0", "id"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "attribute.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "layout.0.type", "hashed"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "comment", "users"),
+				),
+			},
+			// LAYOUT, LIFETIME AND COMMENT ARE REPLACED IN PLACE
+			{
+				Config: dictionaryConfig(testResourceDictionaryDatabaseName, dictionaryName, "flat", "600", "all users"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "layout.0.type", "flat"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "lifetime.0.max", "600"),
+					resource.TestCheckResourceAttr("clickhouse_dictionary.dictionary", "comment", "all users"),
+				),
+			},
+			{
+				ResourceName:            "clickhouse_dictionary.dictionary",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"source.0.password"},
+			},
+		},
+	})
+}
+
+func dictionaryConfig(database string, dictionaryName string, layout string, lifetime string, comment string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+	}
+
+	resource "clickhouse_table" "users" {
+		database = clickhouse_db.new_db_resource.name
+		name = "users"
+		engine = "MergeTree"
+		order_by = ["id"]
+		column {
+			name = "id"
+			type = "UInt64"
+		}
+		column {
+			name = "name"
+			type = "String"
+		}
+	}
+
+	resource "clickhouse_dictionary" "dictionary" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_dictionaryName_%"
+		primary_key = ["id"]
+		attribute {
+			name = "id"
+			type = "UInt64"
+		}
+		attribute {
+			name = "name"
+			type = "String"
+			default = "''"
+		}
+		source {
+			type = "clickhouse"
+			parameters = {
+				db = clickhouse_db.new_db_resource.name
+				table = clickhouse_table.users.name
+			}
+		}
+		layout {
+			type = "%_layout_%"
+		}
+		lifetime {
+			min = 0
+			max = %_lifetime_%
+		}
+		comment = "%_comment_%"
+	}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_dictionaryName_%", dictionaryName, -1)
+	s = strings.Replace(s, "%_layout_%", layout, -1)
+	s = strings.Replace(s, "%_lifetime_%", lifetime, -1)
+	s = strings.Replace(s, "%_comment_%", comment, -1)
+	return s
+}
@@ -0,0 +1,215 @@
+package resourcedictionary
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+// numberRegex matches the values of the numeric parameters, rendered without quotes, e.g. PORT 9000.
+var numberRegex = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// numericSourceParameters are the source parameters taking a number, any other is a string. Parameters are
+// quoted by name rather than by the shape of their value, so an all digits password or table name stays a string.
+var numericSourceParameters = map[string]bool{
+	"port":                        true,
+	"secure":                      true,
+	"update_lag":                  true,
+	"db_index":                    true,
+	"pool_size":                   true,
+	"max_threads":                 true,
+	"max_block_size":              true,
+	"command_termination_timeout": true,
+	"command_read_timeout":        true,
+	"command_write_timeout":       true,
+	"max_command_execution_time":  true,
+	"implicit_key":                true,
+	"execute_direct":              true,
+	"send_chunk_header":           true,
+	"background_reconnect":        true,
+	"connection_pool_size":        true,
+	"connection_max_tries":        true,
+	"connection_wait_timeout":     true,
+}
+
+// stringLayoutParameters are the layout parameters taking a string, the others are numbers.
+var stringLayoutParameters = map[string]bool{
+	"path": true,
+}
+
+type CHDictionaryService struct {
+	CHConnection common.Executor
+}
+
+func (ds *CHDictionaryService) GetDictionary(ctx context.Context, database string, name string) (*CHDictionary, error) {
+	query := "SELECT database, name, status, type, key.names, attribute.names, attribute.types, lifetime_min, lifetime_max, comment FROM system.dictionaries WHERE database = ? AND name = ?"
+	row := ds.CHConnection.QueryRow(ctx, query, database, name)
+
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading dictionary from Clickhouse: %v", row.Err())
+	}
+
+	var chDictionary CHDictionary
+	err := row.ScanStruct(&chDictionary)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse dictionary row: %v", err)
+	}
+
+	// system.dictionaries doesn't report the source and the attribute expressions, they are read from the
+	// definition listed in system.tables
+	query = "SELECT create_table_query FROM system.tables WHERE database = ? AND name = ?"
+	row = ds.CHConnection.QueryRow(ctx, query, database, name)
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading dictionary definition from Clickhouse: %v", row.Err())
+	}
+	err = row.Scan(&chDictionary.CreateTableQuery)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("scanning Clickhouse dictionary definition: %v", err)
+	}
+	return &chDictionary, nil
+}
+
+func (ds *CHDictionaryService) GetDBDictionaries(ctx context.Context, database string) ([]CHDictionary, error) {
+	query := "SELECT database, name FROM system.dictionaries WHERE database = ?"
+	rows, err := ds.CHConnection.Query(ctx, query, database)
+
+	if err != nil {
+		return nil, fmt.Errorf("reading dictionaries from Clickhouse: %v", err)
+	}
+
+	var dictionaries []CHDictionary
+	for rows.Next() {
+		var dictionary CHDictionary
+		err := rows.ScanStruct(&dictionary)
+		if err != nil {
+			return nil, fmt.Errorf("scanning Clickhouse dictionary row: %v", err)
+		}
+		dictionaries = append(dictionaries, dictionary)
+	}
+	return dictionaries, nil
+}
+
+func (ds *CHDictionaryService) CreateDictionary(ctx context.Context, dictionaryResource DictionaryResource) error {
+	err := ds.CHConnection.Exec(ctx, buildCreateDictionaryQuery(dictionaryResource, false))
+	if err != nil {
+		return fmt.Errorf("creating Clickhouse dictionary: %v", err)
+	}
+	return nil
+}
+
+func (ds *CHDictionaryService) ReplaceDictionary(ctx context.Context, dictionaryResource DictionaryResource) error {
+	err := ds.CHConnection.Exec(ctx, buildCreateDictionaryQuery(dictionaryResource, true))
+	if err != nil {
+		return fmt.Errorf("replacing Clickhouse dictionary: %v", err)
+	}
+	return nil
+}
+
+func (ds *CHDictionaryService) DeleteDictionary(ctx context.Context, dictionaryResource DictionaryResource) error {
+	query := fmt.Sprintf("DROP DICTIONARY %s %s", common.QualifiedName(dictionaryResource.Database, dictionaryResource.Name), common.GetClusterStatement(dictionaryResource.Cluster))
+	err := ds.CHConnection.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("deleting Clickhouse dictionary: %v", err)
+	}
+	return nil
+}
+
+func buildCreateDictionaryQuery(dictionaryResource DictionaryResource, replace bool) string {
+	createStatement := "CREATE DICTIONARY"
+	if replace {
+		createStatement = "CREATE OR REPLACE DICTIONARY"
+	}
+
+	var attributes []string
+	for _, attribute := range dictionaryResource.Attributes {
+		attributes = append(attributes, buildAttributeDefinition(attribute))
+	}
+
+	clauses := []string{
+		fmt.Sprintf("PRIMARY KEY %s", strings.Join(common.QuoteIdentifiers(dictionaryResource.PrimaryKey), ", ")),
+		fmt.Sprintf("SOURCE(%s)", buildSourceOrLayout(dictionaryResource.Source.Type, sourceParameters(dictionaryResource.Source), isNumericSourceParameter)),
+		fmt.Sprintf("LAYOUT(%s)", buildSourceOrLayout(dictionaryResource.Layout.Type, dictionaryResource.Layout.Parameters, isNumericLayoutParameter)),
+	}
+	if dictionaryResource.Lifetime != nil {
+		clauses = append(clauses, fmt.Sprintf("LIFETIME(MIN %d MAX %d)", dictionaryResource.Lifetime.Min, dictionaryResource.Lifetime.Max))
+	}
+	if dictionaryResource.Range != nil {
+		clauses = append(clauses, fmt.Sprintf("RANGE(MIN %s MAX %s)", common.QuoteIdentifier(dictionaryResource.Range.Min), common.QuoteIdentifier(dictionaryResource.Range.Max)))
+	}
+	clauses = append(clauses, fmt.Sprintf("COMMENT %s", common.QuoteString(common.GetComment(dictionaryResource.Comment, dictionaryResource.Cluster))))
+
+	return fmt.Sprintf(
+		"%s %s %s (\n\t%s\n)\n%s",
+		createStatement,
+		common.QualifiedName(dictionaryResource.Database, dictionaryResource.Name),
+		common.GetClusterStatement(dictionaryResource.Cluster),
+		strings.Join(attributes, ",\n\t"),
+		strings.Join(clauses, "\n"),
+	)
+}
+
+func buildAttributeDefinition(attribute AttributeResource) string {
+	definition := fmt.Sprintf("%s %s", common.QuoteIdentifier(attribute.Name), attribute.Type)
+	if attribute.Default != "" {
+		definition += " DEFAULT " + attribute.Default
+	}
+	if attribute.Expression != "" {
+		definition += " EXPRESSION " + attribute.Expression
+	}
+	if attribute.Hierarchical {
+		definition += " HIERARCHICAL"
+	}
+	if attribute.Injective {
+		definition += " INJECTIVE"
+	}
+	return definition
+}
+
+func sourceParameters(source SourceResource) map[string]string {
+	if source.Password == "" {
+		return source.Parameters
+	}
+	parameters := map[string]string{"password": source.Password}
+	for key, value := range source.Parameters {
+		parameters[key] = value
+	}
+	return parameters
+}
+
+func isNumericSourceParameter(key string) bool {
+	return numericSourceParameters[strings.ToLower(key)]
+}
+
+func isNumericLayoutParameter(key string) bool {
+	return !stringLayoutParameters[strings.ToLower(key)]
+}
+
+// buildSourceOrLayout renders a source or a layout with its parameters, e.g. CLICKHOUSE(DB 'db' TABLE 'users').
+// Parameters are sorted so the statement doesn't change between runs, numeric ones are rendered without quotes
+// unless their value isn't a number.
+func buildSourceOrLayout(name string, parameters map[string]string, numeric func(string) bool) string {
+	var keys []string
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var items []string
+	for _, key := range keys {
+		value := parameters[key]
+		if !numeric(key) || !numberRegex.MatchString(value) {
+			value = common.QuoteString(value)
+		}
+		items = append(items, fmt.Sprintf("%s %s", strings.ToUpper(key), value))
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(name), strings.Join(items, " "))
+}
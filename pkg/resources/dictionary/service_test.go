@@ -0,0 +1,184 @@
+package resourcedictionary
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+)
+
+func usersDictionary() DictionaryResource {
+	return DictionaryResource{
+		Database:   "db",
+		Name:       "users_dict",
+		PrimaryKey: []string{"id"},
+		Attributes: []AttributeResource{
+			{Name: "id", Type: "UInt64"},
+			{Name: "name", Type: "String", Default: "'unknown'"},
+			{Name: "parent_id", Type: "UInt64", Hierarchical: true},
+		},
+		Source:   SourceResource{Type: "clickhouse", Parameters: map[string]string{"db": "db", "table": "users", "port": "9000"}, Password: "secret"},
+		Layout:   LayoutResource{Type: "hashed"},
+		Lifetime: &LifetimeResource{Min: 0, Max: 300},
+		Comment:  "users",
+	}
+}
+
+func TestGetDictionary(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.dictionaries",
+		chfake.Row{
+			"database":        "db",
+			"name":            "users_dict",
+			"status":          "LOADED",
+			"type":            "Hashed",
+			"key.names":       []string{"id"},
+			"attribute.names": []string{"name", "parent_id"},
+			"attribute.types": []string{"String", "UInt64"},
+			"lifetime_min":    uint64(0),
+			"lifetime_max":    uint64(300),
+			"comment":         `{"provider":"terraform-provider-clickhouse","version":1,"comment":"users","cluster":""}`,
+		},
+		chfake.Row{
+			"database":        "db",
+			"name":            "lazy_dict",
+			"status":          "NOT_LOADED",
+			"key.names":       []string{"id"},
+			"attribute.names": []string{"name", "parent_id"},
+			"attribute.types": []string{"String", "UInt64"},
+		},
+	)
+	fake.AddRows("system.tables", chfake.Row{
+		"database": "db",
+		"name":     "users_dict",
+		"create_table_query": "CREATE DICTIONARY db.users_dict (`id` UInt64, `name` String DEFAULT 'unknown', `parent_id` UInt64 HIERARCHICAL) " +
+			"PRIMARY KEY id SOURCE(CLICKHOUSE(DB 'db' PASSWORD '[HIDDEN]' PORT 9000 TABLE 'users')) LIFETIME(MIN 0 MAX 300) LAYOUT(HASHED()) " +
+			`COMMENT '{"provider":"terraform-provider-clickhouse","version":1,"comment":"users","cluster":""}'`,
+	})
+	service := CHDictionaryService{CHConnection: fake}
+	state := usersDictionary()
+
+	dictionary, err := service.GetDictionary(context.Background(), "db", "users_dict")
+	if err != nil {
+		t.Fatalf("GetDictionary failed: %v", err)
+	}
+	dictionaryResource := dictionary.ToResource()
+	dictionaryResource.KeepState(&state)
+	if !reflect.DeepEqual(*dictionaryResource, state) {
+		t.Errorf("GetDictionary() = %+v, expected %+v", *dictionaryResource, state)
+	}
+
+	// Layout and lifetime of the dictionaries not loaded yet are unknown
+	dictionary, err = service.GetDictionary(context.Background(), "db", "lazy_dict")
+	if err != nil {
+		t.Fatalf("GetDictionary failed: %v", err)
+	}
+	dictionaryResource = dictionary.ToResource()
+	dictionaryResource.KeepState(&state)
+	if dictionaryResource.Layout.Type != "hashed" || dictionaryResource.Lifetime == nil || !reflect.DeepEqual(dictionaryResource.Attributes, state.Attributes) {
+		t.Errorf("unexpected not loaded dictionary %+v", *dictionaryResource)
+	}
+
+	missing, err := service.GetDictionary(context.Background(), "db", "missing")
+	if err != nil || missing != nil {
+		t.Errorf("GetDictionary() of a missing dictionary = %v, %v, expected nil", missing, err)
+	}
+}
+
+func TestKeepStateDrift(t *testing.T) {
+	state := usersDictionary()
+	chDictionary := CHDictionary{
+		Database:       "db",
+		Name:           "users_dict",
+		Type:           "Flat",
+		KeyNames:       []string{"id"},
+		AttributeNames: []string{"name", "email"},
+		AttributeTypes: []string{"String", "String"},
+		LifetimeMax:    600,
+	}
+
+	dictionaryResource := chDictionary.ToResource()
+	dictionaryResource.KeepState(&state)
+	expectedAttributes := []AttributeResource{
+		{Name: "id", Type: "UInt64"},
+		{Name: "name", Type: "String", Default: "'unknown'"},
+		{Name: "email", Type: "String"},
+	}
+	if !reflect.DeepEqual(dictionaryResource.Attributes, expectedAttributes) {
+		t.Errorf("attributes = %+v, expected %+v", dictionaryResource.Attributes, expectedAttributes)
+	}
+	if dictionaryResource.Layout.Type != "flat" || dictionaryResource.Lifetime.Max != 600 {
+		t.Errorf("expected the layout and lifetime changes to be reported as drift, got %+v", *dictionaryResource)
+	}
+	if !reflect.DeepEqual(dictionaryResource.Source, state.Source) {
+		t.Errorf("expected the state source to be kept, got %+v", dictionaryResource.Source)
+	}
+}
+
+func TestKeepStateDefinitionDrift(t *testing.T) {
+	state := usersDictionary()
+	chDictionary := CHDictionary{
+		Database: "db",
+		Name:     "users_dict",
+		CreateTableQuery: "CREATE DICTIONARY db.users_dict (`id` UInt64, `name` String DEFAULT 'anonymous', `parent_id` UInt64 HIERARCHICAL) " +
+			"PRIMARY KEY id SOURCE(CLICKHOUSE(DB 'db' PASSWORD '[HIDDEN]' PORT 9000 TABLE 'people')) LIFETIME(MIN 0 MAX 300) LAYOUT(HASHED()) " +
+			"RANGE(MIN id MAX parent_id)",
+	}
+
+	dictionaryResource := chDictionary.ToResource()
+	dictionaryResource.KeepState(&state)
+	expectedSource := SourceResource{Type: "clickhouse", Parameters: map[string]string{"db": "db", "table": "people", "port": "9000"}, Password: "secret"}
+	if !reflect.DeepEqual(dictionaryResource.Source, expectedSource) {
+		t.Errorf("source = %+v, expected %+v", dictionaryResource.Source, expectedSource)
+	}
+	if dictionaryResource.Attributes[1].Default != "'anonymous'" {
+		t.Errorf("expected the default change to be reported as drift, got %+v", dictionaryResource.Attributes[1])
+	}
+	if !reflect.DeepEqual(dictionaryResource.Range, &RangeResource{Min: "id", Max: "parent_id"}) {
+		t.Errorf("expected the range change to be reported as drift, got %+v", dictionaryResource.Range)
+	}
+}
+
+func TestDictionaryStatements(t *testing.T) {
+	fake := chfake.New()
+	service := CHDictionaryService{CHConnection: fake}
+	ctx := context.Background()
+
+	prices := DictionaryResource{
+		Database:   "db",
+		Name:       "prices_dict",
+		Cluster:    "cluster",
+		PrimaryKey: []string{"product_id"},
+		Attributes: []AttributeResource{
+			{Name: "product_id", Type: "UInt64"},
+			{Name: "start_date", Type: "Date"},
+			{Name: "end_date", Type: "Nullable(Date)"},
+			{Name: "price", Type: "Float64", Expression: "price_cents / 100", Injective: true},
+		},
+		Source: SourceResource{Type: "http", Parameters: map[string]string{"url": "https://example.com/prices.tsv", "format": "TabSeparated"}},
+		Layout: LayoutResource{Type: "range_hashed"},
+		Range:  &RangeResource{Min: "start_date", Max: "end_date"},
+	}
+	cache := usersDictionary()
+	cache.Name = "users_cache"
+	cache.Layout = LayoutResource{Type: "complex_key_cache", Parameters: map[string]string{"size_in_cells": "1000000"}}
+	// Numeric looking values of string parameters stay quoted
+	cache.Source.Parameters = map[string]string{"table": "2024", "port": "9000"}
+	cache.Source.Password = "123456"
+
+	for _, dictionary := range []DictionaryResource{usersDictionary(), prices, cache} {
+		if err := service.CreateDictionary(ctx, dictionary); err != nil {
+			t.Fatalf("CreateDictionary failed: %v", err)
+		}
+	}
+	if err := service.ReplaceDictionary(ctx, prices); err != nil {
+		t.Fatalf("ReplaceDictionary failed: %v", err)
+	}
+	if err := service.DeleteDictionary(ctx, prices); err != nil {
+		t.Fatalf("DeleteDictionary failed: %v", err)
+	}
+	golden.Assert(t, "dictionary_statements", strings.Join(fake.Statements, "\n"))
+}
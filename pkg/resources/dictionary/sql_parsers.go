@@ -0,0 +1,273 @@
+package resourcedictionary
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// definitionToken is a token of a CREATE DICTIONARY statement, with its position so expressions are
+// returned as they were written.
+type definitionToken struct {
+	text  string
+	start int
+	end   int
+}
+
+// attributeKeywords end the type or the expressions of an attribute declaration.
+var attributeKeywords = map[string]bool{
+	"DEFAULT":       true,
+	"EXPRESSION":    true,
+	"HIERARCHICAL":  true,
+	"BIDIRECTIONAL": true,
+	"INJECTIVE":     true,
+	"IS_OBJECT_ID":  true,
+}
+
+// tokenizeDefinition splits a CREATE DICTIONARY statement into words, literals and punctuation.
+func tokenizeDefinition(query string) []definitionToken {
+	var tokens []definitionToken
+	for i := 0; i < len(query); {
+		c := rune(query[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '`' || c == '"':
+			end := i + 1
+			for end < len(query) && query[end] != query[i] {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(query) {
+				end++
+			}
+			tokens = append(tokens, definitionToken{text: query[i:end], start: i, end: end})
+			i = end
+		case c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) || c == '.':
+			end := i
+			for end < len(query) && (query[end] == '_' || query[end] == '.' || unicode.IsLetter(rune(query[end])) || unicode.IsDigit(rune(query[end]))) {
+				end++
+			}
+			tokens = append(tokens, definitionToken{text: query[i:end], start: i, end: end})
+			i = end
+		default:
+			tokens = append(tokens, definitionToken{text: string(c), start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens
+}
+
+// definitionParser reads the parts of a CREATE DICTIONARY statement as formatted by Clickhouse in the
+// create_table_query of system.tables.
+type definitionParser struct {
+	query  string
+	tokens []definitionToken
+	next   int
+}
+
+func (p *definitionParser) peek() string {
+	if p.next >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.next].text
+}
+
+// advance moves to the next token, if any.
+func (p *definitionParser) advance() {
+	if p.next < len(p.tokens) {
+		p.next++
+	}
+}
+
+// skipNested moves past the token at the current position, and when it opens parentheses past the closing one.
+func (p *definitionParser) skipNested() {
+	depth := 0
+	for p.next < len(p.tokens) {
+		switch p.tokens[p.next].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		p.next++
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// textUntil returns the text of the tokens up to one of the stop tokens outside parentheses, e.g. an
+// attribute type or DEFAULT expression.
+func (p *definitionParser) textUntil(stop func(string) bool) string {
+	start := p.next
+	for p.next < len(p.tokens) && !stop(strings.ToUpper(p.peek())) {
+		p.skipNested()
+	}
+	if start == p.next {
+		return ""
+	}
+	return strings.TrimSpace(p.query[p.tokens[start].start:p.tokens[p.next-1].end])
+}
+
+// expect consumes the given token, returning whether it was found.
+func (p *definitionParser) expect(text string) bool {
+	if !strings.EqualFold(p.peek(), text) {
+		return false
+	}
+	p.next++
+	return true
+}
+
+// parseCreateDictionaryQuery reads the definition of a dictionary from its create_table_query, e.g.
+// "CREATE DICTIONARY db.users (`id` UInt64, `name` String DEFAULT 'unknown') PRIMARY KEY id
+// SOURCE(CLICKHOUSE(TABLE 'users')) LIFETIME(MIN 0 MAX 300) LAYOUT(HASHED())". The source password is
+// hidden by Clickhouse so it is left empty, nil is returned when the statement can't be read.
+func parseCreateDictionaryQuery(query string) *DictionaryResource {
+	p := definitionParser{query: query, tokens: tokenizeDefinition(query)}
+	for p.next < len(p.tokens) && p.peek() != "(" {
+		p.advance()
+	}
+	if !p.expect("(") {
+		return nil
+	}
+
+	dictionaryResource := DictionaryResource{}
+	for p.peek() != ")" && p.peek() != "" {
+		attribute := AttributeResource{Name: unquoteIdentifier(p.peek())}
+		p.advance()
+		endOfItem := func(token string) bool { return token == "," || token == ")" || token == "" }
+		endOfPart := func(token string) bool { return endOfItem(token) || attributeKeywords[token] }
+		attribute.Type = p.textUntil(endOfPart)
+		for !endOfItem(p.peek()) {
+			keyword := strings.ToUpper(p.peek())
+			p.advance()
+			switch keyword {
+			case "DEFAULT":
+				attribute.Default = p.textUntil(endOfPart)
+			case "EXPRESSION":
+				attribute.Expression = p.textUntil(endOfPart)
+			case "HIERARCHICAL":
+				attribute.Hierarchical = true
+			case "INJECTIVE":
+				attribute.Injective = true
+			}
+		}
+		dictionaryResource.Attributes = append(dictionaryResource.Attributes, attribute)
+		p.expect(",")
+	}
+	if !p.expect(")") {
+		return nil
+	}
+
+	for p.next < len(p.tokens) {
+		switch strings.ToUpper(p.peek()) {
+		case "PRIMARY":
+			p.advance()
+			p.expect("KEY")
+			for {
+				dictionaryResource.PrimaryKey = append(dictionaryResource.PrimaryKey, unquoteIdentifier(p.peek()))
+				p.advance()
+				if !p.expect(",") {
+					break
+				}
+			}
+		case "SOURCE":
+			p.advance()
+			sourceType, parameters := p.parseNamedParameters()
+			delete(parameters, "password")
+			dictionaryResource.Source = SourceResource{Type: sourceType, Parameters: parameters}
+		case "LAYOUT":
+			p.advance()
+			layoutType, parameters := p.parseNamedParameters()
+			dictionaryResource.Layout = LayoutResource{Type: layoutType, Parameters: parameters}
+		case "LIFETIME":
+			p.advance()
+			lifetime := p.parseMinMax()
+			if lifetime == nil {
+				continue
+			}
+			if min, err := strconv.ParseUint(lifetime.Min, 10, 64); err == nil {
+				max, _ := strconv.ParseUint(lifetime.Max, 10, 64)
+				dictionaryResource.Lifetime = &LifetimeResource{Min: min, Max: max}
+			}
+		case "RANGE":
+			p.advance()
+			dictionaryResource.Range = p.parseMinMax()
+		default:
+			// SETTINGS and COMMENT are not part of the definition
+			p.skipNested()
+		}
+	}
+	return &dictionaryResource
+}
+
+// parseNamedParameters reads the "(TYPE(KEY value ...))" of a source or layout, keys lower cased and string
+// values unquoted. Values that aren't literals, like the nested parameters of some sources, are kept as written.
+func (p *definitionParser) parseNamedParameters() (string, map[string]string) {
+	parameters := make(map[string]string)
+	if !p.expect("(") {
+		return "", parameters
+	}
+	name := strings.ToLower(p.peek())
+	p.advance()
+	if p.expect("(") {
+		for p.peek() != ")" && p.peek() != "" {
+			key := strings.ToLower(p.peek())
+			p.advance()
+			// A value is a literal, possibly negative, or a nested parameters list like HEADERS(...)
+			start := p.next
+			p.expect("-")
+			if p.peek() != "(" {
+				p.advance()
+			}
+			if p.peek() == "(" {
+				p.skipNested()
+			}
+			if start >= len(p.tokens) {
+				break
+			}
+			parameters[key] = unquoteString(p.query[p.tokens[start].start:p.tokens[p.next-1].end])
+		}
+		p.expect(")")
+	}
+	p.expect(")")
+	return name, parameters
+}
+
+// parseMinMax reads "(MIN a MAX b)", a single value like LIFETIME(300) is the max with a 0 min.
+func (p *definitionParser) parseMinMax() *RangeResource {
+	minMax := RangeResource{}
+	if !p.expect("(") {
+		return nil
+	}
+	for p.peek() != ")" && p.peek() != "" {
+		switch {
+		case p.expect("MIN"):
+			minMax.Min = unquoteIdentifier(p.peek())
+		case p.expect("MAX"):
+			minMax.Max = unquoteIdentifier(p.peek())
+		default:
+			minMax.Min, minMax.Max = "0", p.peek()
+		}
+		p.advance()
+	}
+	p.expect(")")
+	return &minMax
+}
+
+func unquoteIdentifier(identifier string) string {
+	if len(identifier) >= 2 && (identifier[0] == '`' || identifier[0] == '"') && identifier[len(identifier)-1] == identifier[0] {
+		return strings.NewReplacer("\\\\", "\\", "\\`", "`", "\\\"", "\"").Replace(identifier[1 : len(identifier)-1])
+	}
+	return identifier
+}
+
+func unquoteString(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.NewReplacer("\\\\", "\\", "\\'", "'").Replace(value[1 : len(value)-1])
+	}
+	return value
+}
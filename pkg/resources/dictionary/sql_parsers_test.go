@@ -0,0 +1,41 @@
+package resourcedictionary
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCreateDictionaryQuery(t *testing.T) {
+	createTableQuery := "CREATE DICTIONARY db.prices_dict (`product_id` UInt64, `start_date` Date, `end_date` Nullable(Date), " +
+		"`price` Decimal(9, 2) DEFAULT 0 EXPRESSION price_cents / 100 INJECTIVE, `odd, name` String DEFAULT 'a, (b)') " +
+		"PRIMARY KEY product_id SOURCE(HTTP(URL 'https://example.com/prices.tsv' FORMAT 'TabSeparated' HEADERS(HEADER(NAME 'a' VALUE 'b')))) " +
+		"LIFETIME(MIN 0 MAX 300) LAYOUT(RANGE_HASHED(RANGE_LOOKUP_STRATEGY 'max')) RANGE(MIN start_date MAX end_date) COMMENT 'prices'"
+
+	expected := &DictionaryResource{
+		PrimaryKey: []string{"product_id"},
+		Attributes: []AttributeResource{
+			{Name: "product_id", Type: "UInt64"},
+			{Name: "start_date", Type: "Date"},
+			{Name: "end_date", Type: "Nullable(Date)"},
+			{Name: "price", Type: "Decimal(9, 2)", Default: "0", Expression: "price_cents / 100", Injective: true},
+			{Name: "odd, name", Type: "String", Default: "'a, (b)'"},
+		},
+		Source: SourceResource{Type: "http", Parameters: map[string]string{
+			"url":     "https://example.com/prices.tsv",
+			"format":  "TabSeparated",
+			"headers": "(HEADER(NAME 'a' VALUE 'b'))",
+		}},
+		Layout:   LayoutResource{Type: "range_hashed", Parameters: map[string]string{"range_lookup_strategy": "max"}},
+		Lifetime: &LifetimeResource{Min: 0, Max: 300},
+		Range:    &RangeResource{Min: "start_date", Max: "end_date"},
+	}
+	if definition := parseCreateDictionaryQuery(createTableQuery); !reflect.DeepEqual(definition, expected) {
+		t.Errorf("parseCreateDictionaryQuery() = %+v, expected %+v", definition, expected)
+	}
+
+	for _, query := range []string{"", "CREATE DICTIONARY db.d", "CREATE DICTIONARY db.d (`id` UInt64"} {
+		if definition := parseCreateDictionaryQuery(query); definition != nil {
+			t.Errorf("parseCreateDictionaryQuery(%q) = %+v, expected nil", query, definition)
+		}
+	}
+}
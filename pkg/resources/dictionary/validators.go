@@ -0,0 +1,32 @@
+package resourcedictionary
+
+import (
+	"fmt"
+	"strings"
+
+	hashicorpcty "github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func ValidateSourceType(inValue any, p hashicorpcty.Path) diag.Diagnostics {
+	return validateOneOf(inValue.(string), SourceTypes)
+}
+
+func ValidateLayoutType(inValue any, p hashicorpcty.Path) diag.Diagnostics {
+	return validateOneOf(inValue.(string), LayoutTypes)
+}
+
+func validateOneOf(value string, allowed []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, allowedValue := range allowed {
+		if strings.ToLower(value) == allowedValue {
+			return diags
+		}
+	}
+	diag := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "wrong value",
+		Detail:   fmt.Sprintf("%q is not one of %s", value, strings.Join(allowed, ", ")),
+	}
+	return append(diags, diag)
+}
@@ -0,0 +1,52 @@
+package resourcefunction
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+// createFunctionRegex splits the create_query reported by system.functions into the parameters and the
+// expression, a single parameter may come without parentheses: CREATE FUNCTION f AS x -> x + 1
+var createFunctionRegex = regexp.MustCompile("(?is)^\\s*CREATE\\s+(?:OR\\s+REPLACE\\s+)?FUNCTION\\s+.+?\\s+AS\\s+(?:\\(([^)]*)\\)|(\\w+|`[^`]*`))\\s*->\\s*(.+?)\\s*;?\\s*$")
+
+type CHFunction struct {
+	Name        string `ch:"name"`
+	CreateQuery string `ch:"create_query"`
+}
+
+type FunctionResource struct {
+	Name       string
+	Cluster    string
+	Parameters []string
+	Expression string
+}
+
+func (f *CHFunction) ToResource() (*FunctionResource, error) {
+	matches := createFunctionRegex.FindStringSubmatch(f.CreateQuery)
+	if matches == nil {
+		return nil, fmt.Errorf("unexpected create query %q of function %s", f.CreateQuery, f.Name)
+	}
+
+	functionResource := FunctionResource{
+		Name:       f.Name,
+		Expression: matches[3],
+	}
+	for _, parameter := range strings.Split(matches[1]+matches[2], ",") {
+		if parameter = strings.Trim(strings.TrimSpace(parameter), "`"); parameter != "" {
+			functionResource.Parameters = append(functionResource.Parameters, parameter)
+		}
+	}
+	return &functionResource, nil
+}
+
+// KeepState keeps the parameters and expression written in the configuration when the function
+// Clickhouse stores is equivalent, as its create query is reformatted, e.g. k * x + b is stored as ((k * x) + b).
+func (f *FunctionResource) KeepState(state *FunctionResource) {
+	if common.QueriesEquivalent(buildFunctionDefinition(*state), buildFunctionDefinition(*f), "") {
+		f.Parameters = state.Parameters
+		f.Expression = state.Expression
+	}
+}
@@ -0,0 +1,180 @@
+package resourcefunction
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceFunction() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage SQL user defined functions",
+
+		CreateContext: resourceFunctionCreate,
+		ReadContext:   resourceFunctionRead,
+		UpdateContext: resourceFunctionUpdate,
+		DeleteContext: resourceFunctionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFunctionImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description:      "Function name, functions are global so it must be unique in the server",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: ValidateIdentifier,
+			},
+			"cluster": {
+				Description: "Cluster name, the provider default cluster is used when not provided",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"parameters": {
+				Description: "Function parameters, referenced by the expression",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: ValidateIdentifier,
+				},
+			},
+			"expression": {
+				Description: "Function body, e.g. `replaceRegexpOne(url, '^https?://([^/]+).*', '\\\\1')`",
+				Type:        schema.TypeString,
+				Required:    true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return common.QueriesEquivalent(old, new, "")
+				},
+			},
+		},
+	}
+}
+
+func getFunctionResource(d *schema.ResourceData, defaultCluster string) FunctionResource {
+	functionResource := FunctionResource{
+		Name:       d.Get("name").(string),
+		Cluster:    d.Get("cluster").(string),
+		Parameters: common.MapArrayInterfaceToArrayOfStrings(d.Get("parameters").([]interface{})),
+		Expression: d.Get("expression").(string),
+	}
+	if functionResource.Cluster == "" {
+		functionResource.Cluster = defaultCluster
+	}
+	return functionResource
+}
+
+func resourceFunctionRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chFunctionService := CHFunctionService{CHConnection: client.ClickhouseConnection}
+
+	functionName := d.Get("name").(string)
+
+	chFunction, err := chFunctionService.GetFunction(ctx, functionName)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse function: %v", err))
+	}
+	if chFunction == nil {
+		tflog.Warn(ctx, "Function not found, removing it from state", map[string]interface{}{"function": functionName})
+		d.SetId("")
+		return diags
+	}
+
+	functionResource, err := chFunction.ToResource()
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("transforming Clickhouse function to resource: %v", err))
+	}
+	stateFunction := getFunctionResource(d, "")
+	functionResource.KeepState(&stateFunction)
+	// Functions have no comment to record the cluster in, keep the one already known
+	functionResource.Cluster = stateFunction.Cluster
+
+	if err := d.Set("name", functionResource.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %v", err))
+	}
+	if err := d.Set("cluster", functionResource.Cluster); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster: %v", err))
+	}
+	if err := d.Set("parameters", functionResource.Parameters); err != nil {
+		return diag.FromErr(fmt.Errorf("setting parameters: %v", err))
+	}
+	if err := d.Set("expression", functionResource.Expression); err != nil {
+		return diag.FromErr(fmt.Errorf("setting expression: %v", err))
+	}
+
+	d.SetId(functionResource.Cluster + ":" + functionName)
+
+	return diags
+}
+
+func resourceFunctionImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Same ID format written by resourceFunctionCreate, the cluster part may be empty: <cluster>:<function>
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) < 2 || parts[len(parts)-1] == "" {
+		return nil, fmt.Errorf("unexpected import id %q, expected <cluster>:<function>", d.Id())
+	}
+
+	if err := d.Set("cluster", strings.Join(parts[:len(parts)-1], ":")); err != nil {
+		return nil, fmt.Errorf("setting cluster: %v", err)
+	}
+	if err := d.Set("name", parts[len(parts)-1]); err != nil {
+		return nil, fmt.Errorf("setting name: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceFunctionCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chFunctionService := CHFunctionService{CHConnection: client.ClickhouseConnection}
+	functionResource := getFunctionResource(d, client.DefaultCluster)
+
+	if err := chFunctionService.ReplaceFunction(ctx, functionResource); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(functionResource.Cluster + ":" + functionResource.Name)
+
+	return diags
+}
+
+func resourceFunctionUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chFunctionService := CHFunctionService{CHConnection: client.ClickhouseConnection}
+
+	if err := chFunctionService.ReplaceFunction(ctx, getFunctionResource(d, client.DefaultCluster)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceFunctionDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chFunctionService := CHFunctionService{CHConnection: client.ClickhouseConnection}
+
+	if err := chFunctionService.DeleteFunction(ctx, getFunctionResource(d, client.DefaultCluster)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
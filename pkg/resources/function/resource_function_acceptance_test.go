@@ -0,0 +1,69 @@
+package resourcefunction_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceFunction(t *testing.T) {
+	const functionName = "acc_test_linear"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: functionConfig(functionName, `"x", "k", "b"`, "k*x + b"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_function.function", "name", functionName),
+					resource.TestCheckResourceAttr("clickhouse_function.function", "parameters.#", "3"),
+					resource.TestCheckResourceAttr("clickhouse_function.function", "expression", "k*x + b"),
+				),
+			},
+			// REFORMATTED EXPRESSION DOESN'T CHANGE THE FUNCTION
+			{
+				Config:   functionConfig(functionName, `"x", "k", "b"`, "k * x + b"),
+				PlanOnly: true,
+			},
+			{
+				Config:   functionConfig(functionName, `"x", "k", "b"`, "((k * x) + b)"),
+				PlanOnly: true,
+			},
+			// PARAMETERS AND EXPRESSION ARE REPLACED IN PLACE
+			{
+				Config: functionConfig(functionName, `"x", "k"`, "k * x"),
+				Check:  resource.TestCheckResourceAttr("clickhouse_function.function", "parameters.#", "2"),
+			},
+			{
+				ResourceName:            "clickhouse_function.function",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"expression"},
+			},
+			// FUNCTION CHANGED OUTSIDE TERRAFORM IS REPORTED
+			{
+				PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("CREATE OR REPLACE FUNCTION %s AS (x, k) -> k + x", functionName)),
+				Config:             functionConfig(functionName, `"x", "k"`, "k * x"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func functionConfig(functionName string, parameters string, expression string) string {
+	s := `
+	resource "clickhouse_function" "function" {
+		name = "%_functionName_%"
+		parameters = [%_parameters_%]
+		expression = "%_expression_%"
+	}`
+
+	s = strings.Replace(s, "%_functionName_%", functionName, -1)
+	s = strings.Replace(s, "%_parameters_%", parameters, -1)
+	s = strings.Replace(s, "%_expression_%", expression, -1)
+	return s
+}
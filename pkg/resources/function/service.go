@@ -0,0 +1,74 @@
+package resourcefunction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+const sqlUserDefinedOrigin = "SQLUserDefined"
+
+type CHFunctionService struct {
+	CHConnection common.Executor
+}
+
+func (fs *CHFunctionService) GetFunction(ctx context.Context, name string) (*CHFunction, error) {
+	query := "SELECT name, create_query FROM system.functions WHERE name = ? AND origin = ?"
+	row := fs.CHConnection.QueryRow(ctx, query, name, sqlUserDefinedOrigin)
+
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading function from Clickhouse: %v", row.Err())
+	}
+
+	var chFunction CHFunction
+	err := row.ScanStruct(&chFunction)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse function row: %v", err)
+	}
+	return &chFunction, nil
+}
+
+func (fs *CHFunctionService) ReplaceFunction(ctx context.Context, functionResource FunctionResource) error {
+	err := fs.CHConnection.Exec(ctx, buildCreateFunctionQuery(functionResource))
+	if err != nil {
+		return fmt.Errorf("creating Clickhouse function: %v", err)
+	}
+	return nil
+}
+
+func (fs *CHFunctionService) DeleteFunction(ctx context.Context, functionResource FunctionResource) error {
+	query := fmt.Sprintf("DROP FUNCTION %s %s", common.QuoteIdentifier(functionResource.Name), common.GetClusterStatement(functionResource.Cluster))
+	err := fs.CHConnection.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("deleting Clickhouse function: %v", err)
+	}
+	return nil
+}
+
+func buildCreateFunctionQuery(functionResource FunctionResource) string {
+	return fmt.Sprintf(
+		"CREATE OR REPLACE FUNCTION %s %s AS (%s) -> %s",
+		common.QuoteIdentifier(functionResource.Name),
+		common.GetClusterStatement(functionResource.Cluster),
+		strings.Join(common.QuoteIdentifiers(functionResource.Parameters), ", "),
+		strings.TrimRight(strings.TrimSpace(functionResource.Expression), ";"),
+	)
+}
+
+// buildFunctionDefinition renders the function without the OR REPLACE and ON CLUSTER clauses, to
+// compare it with the create query reported by system.functions.
+func buildFunctionDefinition(functionResource FunctionResource) string {
+	return fmt.Sprintf(
+		"CREATE FUNCTION %s AS (%s) -> %s",
+		common.QuoteIdentifier(functionResource.Name),
+		strings.Join(common.QuoteIdentifiers(functionResource.Parameters), ", "),
+		strings.TrimRight(strings.TrimSpace(functionResource.Expression), ";"),
+	)
+}
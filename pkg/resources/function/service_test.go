@@ -0,0 +1,95 @@
+package resourcefunction
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+)
+
+func TestGetFunction(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.functions",
+		chfake.Row{"name": "plus", "origin": "System", "create_query": ""},
+		chfake.Row{"name": "domain_of", "origin": "SQLUserDefined", "create_query": "CREATE FUNCTION domain_of AS url -> domain(lower(url))"},
+		chfake.Row{"name": "linear", "origin": "SQLUserDefined", "create_query": "CREATE FUNCTION linear AS (x, k, b) -> ((k * x) + b)"},
+	)
+	service := CHFunctionService{CHConnection: fake}
+
+	function, err := service.GetFunction(context.Background(), "linear")
+	if err != nil {
+		t.Fatalf("GetFunction failed: %v", err)
+	}
+	functionResource, err := function.ToResource()
+	if err != nil {
+		t.Fatalf("ToResource failed: %v", err)
+	}
+	expected := FunctionResource{Name: "linear", Parameters: []string{"x", "k", "b"}, Expression: "((k * x) + b)"}
+	if !reflect.DeepEqual(*functionResource, expected) {
+		t.Errorf("GetFunction() = %+v, expected %+v", *functionResource, expected)
+	}
+
+	// Clickhouse stores the expression fully parenthesized
+	state := FunctionResource{Name: "linear", Parameters: []string{"x", "k", "b"}, Expression: "k * x + b\n"}
+	functionResource.KeepState(&state)
+	if functionResource.Expression != state.Expression {
+		t.Errorf("expected the equivalent state expression to be kept, got %q", functionResource.Expression)
+	}
+
+	functionResource, _ = function.ToResource()
+	regrouped := FunctionResource{Name: "linear", Parameters: []string{"x", "k", "b"}, Expression: "k * (x + b)"}
+	functionResource.KeepState(&regrouped)
+	if functionResource.Expression != "((k * x) + b)" {
+		t.Errorf("expected the regrouped expression to be reported as drift, got %q", functionResource.Expression)
+	}
+
+	functionResource, _ = function.ToResource()
+	changed := FunctionResource{Name: "linear", Parameters: []string{"x", "k"}, Expression: "k * x"}
+	functionResource.KeepState(&changed)
+	if !reflect.DeepEqual(functionResource.Parameters, []string{"x", "k", "b"}) || functionResource.Expression != "((k * x) + b)" {
+		t.Errorf("expected the changed function to be reported as drift, got %+v", *functionResource)
+	}
+
+	for _, name := range []string{"plus", "missing"} {
+		missing, err := service.GetFunction(context.Background(), name)
+		if err != nil || missing != nil {
+			t.Errorf("GetFunction(%q) = %v, %v, expected nil", name, missing, err)
+		}
+	}
+
+	// Single parameter functions may be stored without parentheses
+	function, _ = service.GetFunction(context.Background(), "domain_of")
+	functionResource, err = function.ToResource()
+	if err != nil {
+		t.Fatalf("ToResource failed: %v", err)
+	}
+	functionResource.KeepState(&FunctionResource{Name: "domain_of", Parameters: []string{"url"}, Expression: "domain(lower(url))"})
+	expected = FunctionResource{Name: "domain_of", Parameters: []string{"url"}, Expression: "domain(lower(url))"}
+	if !reflect.DeepEqual(*functionResource, expected) {
+		t.Errorf("GetFunction() = %+v, expected %+v", *functionResource, expected)
+	}
+}
+
+func TestFunctionStatements(t *testing.T) {
+	fake := chfake.New()
+	service := CHFunctionService{CHConnection: fake}
+	ctx := context.Background()
+
+	functions := []FunctionResource{
+		{Name: "linear", Parameters: []string{"x", "k", "b"}, Expression: "k * x + b;"},
+		{Name: "domain_of", Cluster: "cluster", Parameters: []string{"url"}, Expression: "domain(lower(url))"},
+		{Name: "answer", Expression: "42"},
+	}
+	for _, function := range functions {
+		if err := service.ReplaceFunction(ctx, function); err != nil {
+			t.Fatalf("ReplaceFunction failed: %v", err)
+		}
+		if err := service.DeleteFunction(ctx, function); err != nil {
+			t.Fatalf("DeleteFunction failed: %v", err)
+		}
+	}
+	golden.Assert(t, "function_statements", strings.Join(fake.Statements, "\n"))
+}
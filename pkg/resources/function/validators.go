@@ -0,0 +1,25 @@
+package resourcefunction
+
+import (
+	"fmt"
+	"regexp"
+
+	hashicorpcty "github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func ValidateIdentifier(inValue any, p hashicorpcty.Path) diag.Diagnostics {
+	value := inValue.(string)
+	var diags diag.Diagnostics
+	if identifierRegex.MatchString(value) {
+		return diags
+	}
+	diag := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "wrong value",
+		Detail:   fmt.Sprintf("%q is not a valid identifier, it must start with a letter or an underscore followed by letters, digits or underscores", value),
+	}
+	return append(diags, diag)
+}
@@ -0,0 +1,120 @@
+package resourcegrant
+
+import (
+	"sort"
+	"strings"
+)
+
+// wildcard stands for every database or table, Clickhouse reports it as a NULL database or table.
+const wildcard = "*"
+
+type CHGrant struct {
+	Grantee         string `ch:"grantee"`
+	AccessType      string `ch:"access_type"`
+	Database        string `ch:"database"`
+	Table           string `ch:"table"`
+	Column          string `ch:"column"`
+	IsPartialRevoke uint8  `ch:"is_partial_revoke"`
+	GrantOption     uint8  `ch:"grant_option"`
+}
+
+type GrantResource struct {
+	Grantee         string
+	Database        string
+	Table           string
+	Columns         []string
+	Privileges      []string
+	WithGrantOption bool
+}
+
+// ToGrantResource returns the privileges of grant granted on its scope (its grantee, database, table and
+// columns) according to the system.grants rows. A privilege is granted when every column of the scope, or
+// the whole table when there are no columns, has a row for it which isn't a partial revoke. The grant
+// option is set when all of those rows have it.
+// Only the privileges of grant are reported, so the ones granted on the same scope by other resources, like
+// another clickhouse_grant or a clickhouse_role, aren't taken for drift. Every privilege of the scope is
+// reported when grant has none, e.g. on import.
+func ToGrantResource(grant GrantResource, chGrants []CHGrant) *GrantResource {
+	columns := make(map[string]bool)
+	for _, column := range grant.Columns {
+		columns[column] = true
+	}
+	// Privileges are matched case insensitively, keeping the spelling of grant
+	managed := make(map[string]string)
+	for _, privilege := range grant.Privileges {
+		managed[normalizePrivilege(privilege)] = privilege
+	}
+
+	grantedColumns := make(map[string]map[string]bool)
+	revoked := make(map[string]bool)
+	withoutGrantOption := make(map[string]bool)
+	for _, chGrant := range chGrants {
+		if chGrant.Grantee != grant.Grantee || orWildcard(chGrant.Database) != grant.Database || orWildcard(chGrant.Table) != grant.Table {
+			continue
+		}
+		if (len(columns) == 0 && chGrant.Column != "") || (len(columns) > 0 && !columns[chGrant.Column]) {
+			continue
+		}
+		accessType := chGrant.AccessType
+		if len(managed) > 0 {
+			privilege, ok := managed[normalizePrivilege(accessType)]
+			if !ok {
+				continue
+			}
+			accessType = privilege
+		}
+		if chGrant.IsPartialRevoke != 0 {
+			revoked[accessType] = true
+			continue
+		}
+		if grantedColumns[accessType] == nil {
+			grantedColumns[accessType] = make(map[string]bool)
+		}
+		grantedColumns[accessType][chGrant.Column] = true
+		if chGrant.GrantOption == 0 {
+			withoutGrantOption[accessType] = true
+		}
+	}
+
+	grantResource := GrantResource{
+		Grantee:         grant.Grantee,
+		Database:        grant.Database,
+		Table:           grant.Table,
+		Columns:         grant.Columns,
+		WithGrantOption: true,
+	}
+	for accessType, granted := range grantedColumns {
+		if revoked[accessType] || len(granted) != max(len(columns), 1) {
+			continue
+		}
+		grantResource.Privileges = append(grantResource.Privileges, accessType)
+		if withoutGrantOption[accessType] {
+			grantResource.WithGrantOption = false
+		}
+	}
+	sort.Strings(grantResource.Privileges)
+	if len(grantResource.Privileges) == 0 {
+		grantResource.WithGrantOption = false
+	}
+	return &grantResource
+}
+
+// normalizePrivilege returns the form privileges are compared in, as Clickhouse keywords are case insensitive,
+// e.g. "alter  update" is "ALTER UPDATE".
+func normalizePrivilege(privilege string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(privilege), " "))
+}
+
+func orWildcard(name string) string {
+	if name == "" {
+		return wildcard
+	}
+	return name
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
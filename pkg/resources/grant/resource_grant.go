@@ -0,0 +1,253 @@
+package resourcegrant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceGrant() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage the privileges granted to a user or a role on a database, table or columns",
+
+		CreateContext: resourceGrantCreate,
+		ReadContext:   resourceGrantRead,
+		UpdateContext: resourceGrantUpdate,
+		DeleteContext: resourceGrantDelete,
+		CustomizeDiff: resourceGrantCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceGrantImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"grantee": {
+				Description: "User or role the privileges are granted to",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"privileges": {
+				Description: "Granted privileges, e.g. `SELECT`, `ALTER UPDATE` or `dictGet`, compared case insensitively",
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"database": {
+				Description: "Database the privileges apply to, '*' for every database",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     wildcard,
+				ForceNew:    true,
+			},
+			"table": {
+				Description: "Table the privileges apply to, '*' for every table of the database",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     wildcard,
+				ForceNew:    true,
+			},
+			"columns": {
+				Description: "Columns of the table the privileges apply to, the whole table when not provided",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"with_grant_option": {
+				Description: "Allow the grantee to grant these privileges to others",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+		},
+	}
+}
+
+func getGrantResource(d *schema.ResourceData) GrantResource {
+	grantResource := GrantResource{
+		Grantee:         d.Get("grantee").(string),
+		Database:        d.Get("database").(string),
+		Table:           d.Get("table").(string),
+		Columns:         common.StringSetToList(d.Get("columns").(*schema.Set)),
+		Privileges:      common.StringSetToList(d.Get("privileges").(*schema.Set)),
+		WithGrantOption: d.Get("with_grant_option").(bool),
+	}
+	sort.Strings(grantResource.Columns)
+	return grantResource
+}
+
+// Clickhouse exception codes of a grantee that doesn't exist, its privileges are then already gone.
+const (
+	unknownUserCode int32 = 192
+	unknownRoleCode int32 = 511
+)
+
+func grantId(grantResource GrantResource) string {
+	return strings.Join([]string{grantResource.Grantee, grantResource.Database, grantResource.Table, strings.Join(grantResource.Columns, ",")}, ":")
+}
+
+// splitGrantId returns the parts of a grant ID, split from the right so a grantee may contain ':'. Nil is
+// returned when the ID has less than 4 parts.
+func splitGrantId(id string) []string {
+	parts := make([]string, 4)
+	for i := 3; i > 0; i-- {
+		separator := strings.LastIndex(id, ":")
+		if separator < 0 {
+			return nil
+		}
+		parts[i] = id[separator+1:]
+		id = id[:separator]
+	}
+	parts[0] = id
+	return parts
+}
+
+func resourceGrantCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	database := d.Get("database").(string)
+	table := d.Get("table").(string)
+	if table != wildcard && database == wildcard {
+		return fmt.Errorf("table %q requires a database, privileges on a table of every database can't be granted", table)
+	}
+	if d.Get("columns").(*schema.Set).Len() > 0 && table == wildcard {
+		return fmt.Errorf("columns require a table, privileges on the columns of every table can't be granted")
+	}
+	return nil
+}
+
+func resourceGrantRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chGrantService := CHGrantService{CHConnection: client.ClickhouseConnection}
+	stateGrant := getGrantResource(d)
+
+	chGrants, err := chGrantService.GetGrants(ctx, stateGrant.Grantee)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse grants: %v", err))
+	}
+
+	grantResource := ToGrantResource(stateGrant, chGrants)
+	if len(grantResource.Privileges) == 0 {
+		tflog.Warn(ctx, "Grant not found, removing it from state", map[string]interface{}{"grantee": stateGrant.Grantee, "database": stateGrant.Database, "table": stateGrant.Table})
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("privileges", common.StringListToSet(grantResource.Privileges)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting privileges: %v", err))
+	}
+	if err := d.Set("with_grant_option", grantResource.WithGrantOption); err != nil {
+		return diag.FromErr(fmt.Errorf("setting with_grant_option: %v", err))
+	}
+
+	d.SetId(grantId(*grantResource))
+
+	return diags
+}
+
+func resourceGrantImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Same ID format written by resourceGrantCreate, the columns part may be empty: <grantee>:<database>:<table>:<columns>
+	parts := splitGrantId(d.Id())
+	if parts == nil || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("unexpected import id %q, expected <grantee>:<database>:<table>:<comma separated columns>", d.Id())
+	}
+
+	if err := d.Set("grantee", parts[0]); err != nil {
+		return nil, fmt.Errorf("setting grantee: %v", err)
+	}
+	if err := d.Set("database", parts[1]); err != nil {
+		return nil, fmt.Errorf("setting database: %v", err)
+	}
+	if err := d.Set("table", parts[2]); err != nil {
+		return nil, fmt.Errorf("setting table: %v", err)
+	}
+	var columns []string
+	if parts[3] != "" {
+		columns = strings.Split(parts[3], ",")
+	}
+	if err := d.Set("columns", common.StringListToSet(columns)); err != nil {
+		return nil, fmt.Errorf("setting columns: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceGrantCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chGrantService := CHGrantService{CHConnection: client.ClickhouseConnection}
+	grantResource := getGrantResource(d)
+
+	if err := chGrantService.Grant(ctx, grantResource, grantResource.Privileges, grantResource.WithGrantOption); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(grantId(grantResource))
+
+	return diags
+}
+
+func resourceGrantUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chGrantService := CHGrantService{CHConnection: client.ClickhouseConnection}
+	grantResource := getGrantResource(d)
+
+	statePrivileges, planPrivileges := d.GetChange("privileges")
+	revokePrivileges := common.StringSetToList(statePrivileges.(*schema.Set).Difference(planPrivileges.(*schema.Set)))
+	keptPrivileges := common.StringSetToList(statePrivileges.(*schema.Set).Intersection(planPrivileges.(*schema.Set)))
+	grantPrivileges := common.StringSetToList(planPrivileges.(*schema.Set).Difference(statePrivileges.(*schema.Set)))
+
+	if err := chGrantService.Revoke(ctx, grantResource, revokePrivileges); err != nil {
+		return diag.FromErr(err)
+	}
+	if d.HasChange("with_grant_option") {
+		if grantResource.WithGrantOption {
+			// Granting the privileges again adds the grant option to the ones already granted
+			grantPrivileges = grantResource.Privileges
+		} else if err := chGrantService.RevokeGrantOption(ctx, grantResource, keptPrivileges); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err := chGrantService.Grant(ctx, grantResource, grantPrivileges, grantResource.WithGrantOption); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceGrantDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chGrantService := CHGrantService{CHConnection: client.ClickhouseConnection}
+	grantResource := getGrantResource(d)
+
+	err := chGrantService.Revoke(ctx, grantResource, grantResource.Privileges)
+	if common.IsExceptionCode(err, unknownUserCode, unknownRoleCode) {
+		tflog.Warn(ctx, "Grantee not found, its privileges are already revoked", map[string]interface{}{"grantee": grantResource.Grantee})
+		return diags
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
@@ -0,0 +1,111 @@
+package resourcegrant_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testResourceGrantDatabaseName = "grant_test_database"
+const testResourceGrantUserName = "grant_test_user"
+
+func TestAccResourceGrant(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: grantConfig(`"SELECT", "INSERT"`, `[]`, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_grant.grant", "privileges.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_grant.grant", "table", "events"),
+					resource.TestCheckResourceAttr("clickhouse_grant.grant", "with_grant_option", "false"),
+				),
+			},
+			// PRIVILEGES AND GRANT OPTION ARE CHANGED IN PLACE
+			{
+				Config: grantConfig(`"SELECT"`, `[]`, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_grant.grant", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_grant.grant", "with_grant_option", "true"),
+				),
+			},
+			{
+				ResourceName:      "clickhouse_grant.grant",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// PRIVILEGES REVOKED OUTSIDE TERRAFORM ARE REPORTED
+			{
+				PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("REVOKE SELECT ON %s.events FROM %s", testResourceGrantDatabaseName, testResourceGrantUserName)),
+				Config:             grantConfig(`"SELECT"`, `[]`, true),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceGrant_Columns(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: grantConfig(`"SELECT"`, `["key", "eventTime"]`, false),
+				Check:  resource.TestCheckResourceAttr("clickhouse_grant.grant", "columns.#", "2"),
+			},
+			{
+				ResourceName:      "clickhouse_grant.grant",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func grantConfig(privileges string, columns string, withGrantOption bool) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+	}
+
+	resource "clickhouse_table" "events" {
+		database = clickhouse_db.new_db_resource.name
+		name = "events"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "Int64"
+		}
+		column {
+			name = "eventTime"
+			type = "DateTime"
+		}
+	}
+
+	resource "clickhouse_user" "user" {
+		name = "%_user_%"
+		password = "grant_test_password"
+	}
+
+	resource "clickhouse_grant" "grant" {
+		grantee = clickhouse_user.user.name
+		privileges = [%_privileges_%]
+		database = clickhouse_db.new_db_resource.name
+		table = clickhouse_table.events.name
+		columns = %_columns_%
+		with_grant_option = %_withGrantOption_%
+	}`
+
+	s = strings.Replace(s, "%_database_%", testResourceGrantDatabaseName, -1)
+	s = strings.Replace(s, "%_user_%", testResourceGrantUserName, -1)
+	s = strings.Replace(s, "%_privileges_%", privileges, -1)
+	s = strings.Replace(s, "%_columns_%", columns, -1)
+	s = strings.Replace(s, "%_withGrantOption_%", fmt.Sprint(withGrantOption), -1)
+	return s
+}
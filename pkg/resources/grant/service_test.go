@@ -0,0 +1,154 @@
+package resourcegrant
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+)
+
+func TestGetGrants(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.grants",
+		chfake.Row{"grantee": "analyst", "access_type": "SELECT", "database": "db", "table": "events", "grant_option": uint8(1)},
+		chfake.Row{"grantee": "analyst", "access_type": "INSERT", "database": "db", "table": "events"},
+		chfake.Row{"grantee": "analyst", "access_type": "SELECT", "database": "db", "table": "users", "column": "id"},
+		chfake.Row{"grantee": "analyst", "access_type": "SELECT", "database": "db", "table": "users", "column": "name"},
+		chfake.Row{"grantee": "analyst", "access_type": "INSERT", "database": "db", "table": "users", "column": "id"},
+		chfake.Row{"grantee": "analyst", "access_type": "SHOW TABLES", "database": "db"},
+		chfake.Row{"grantee": "analyst", "access_type": "SELECT", "database": "db", "table": "secrets", "is_partial_revoke": uint8(1)},
+		chfake.Row{"grantee": "analyst", "access_type": "CREATE FUNCTION"},
+		chfake.Row{"grantee": "writer", "access_type": "SELECT", "database": "db", "table": "events"},
+	)
+	service := CHGrantService{CHConnection: fake}
+
+	grants, err := service.GetGrants(context.Background(), "analyst")
+	if err != nil {
+		t.Fatalf("GetGrants failed: %v", err)
+	}
+	if len(grants) != 8 {
+		t.Fatalf("unexpected grants %+v", grants)
+	}
+
+	tests := []struct {
+		scope    GrantResource
+		expected GrantResource
+	}{
+		{
+			scope:    GrantResource{Grantee: "analyst", Database: "db", Table: "events"},
+			expected: GrantResource{Grantee: "analyst", Database: "db", Table: "events", Privileges: []string{"INSERT", "SELECT"}},
+		},
+		{
+			scope:    GrantResource{Grantee: "analyst", Database: "db", Table: "users", Columns: []string{"id", "name"}},
+			expected: GrantResource{Grantee: "analyst", Database: "db", Table: "users", Columns: []string{"id", "name"}, Privileges: []string{"SELECT"}},
+		},
+		{
+			scope:    GrantResource{Grantee: "analyst", Database: "db", Table: "*"},
+			expected: GrantResource{Grantee: "analyst", Database: "db", Table: "*", Privileges: []string{"SHOW TABLES"}},
+		},
+		{
+			scope:    GrantResource{Grantee: "analyst", Database: "*", Table: "*"},
+			expected: GrantResource{Grantee: "analyst", Database: "*", Table: "*", Privileges: []string{"CREATE FUNCTION"}},
+		},
+		{
+			scope:    GrantResource{Grantee: "analyst", Database: "db", Table: "secrets"},
+			expected: GrantResource{Grantee: "analyst", Database: "db", Table: "secrets"},
+		},
+	}
+	for _, test := range tests {
+		actual := ToGrantResource(test.scope, grants)
+		if !reflect.DeepEqual(*actual, test.expected) {
+			t.Errorf("ToGrantResource(%+v) = %+v, expected %+v", test.scope, *actual, test.expected)
+		}
+	}
+
+	// Only the privileges managed by the resource are read, whatever their case
+	managed := ToGrantResource(GrantResource{Grantee: "analyst", Database: "db", Table: "events", Privileges: []string{"select", "ALTER UPDATE"}}, grants)
+	expected := GrantResource{Grantee: "analyst", Database: "db", Table: "events", Privileges: []string{"select"}, WithGrantOption: true}
+	if !reflect.DeepEqual(*managed, expected) {
+		t.Errorf("ToGrantResource() = %+v, expected %+v", *managed, expected)
+	}
+
+	withGrantOption := ToGrantResource(GrantResource{Grantee: "analyst", Database: "db", Table: "events"}, []CHGrant{grants[0]})
+	if !withGrantOption.WithGrantOption {
+		t.Errorf("expected the grant option to be read, got %+v", *withGrantOption)
+	}
+}
+
+func TestGrantStatements(t *testing.T) {
+	fake := chfake.New()
+	service := CHGrantService{CHConnection: fake}
+	ctx := context.Background()
+
+	table := GrantResource{Grantee: "analyst", Database: "db", Table: "events"}
+	columns := GrantResource{Grantee: "analyst", Database: "db", Table: "users", Columns: []string{"id", "name"}}
+	global := GrantResource{Grantee: "admin", Database: "*", Table: "*"}
+
+	steps := []func() error{
+		func() error { return service.Grant(ctx, table, []string{"SELECT", "INSERT"}, false) },
+		func() error { return service.Grant(ctx, columns, []string{"SELECT"}, true) },
+		func() error { return service.Grant(ctx, global, []string{"CREATE FUNCTION", "DROP FUNCTION"}, false) },
+		func() error { return service.Grant(ctx, table, nil, false) },
+		func() error { return service.RevokeGrantOption(ctx, columns, []string{"SELECT"}) },
+		func() error { return service.Revoke(ctx, table, []string{"INSERT"}) },
+		func() error { return service.Revoke(ctx, global, []string{"DROP FUNCTION"}) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("grant statement failed: %v", err)
+		}
+	}
+	golden.Assert(t, "grant_statements", strings.Join(fake.Statements, "\n"))
+}
+
+func TestSplitGrantId(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected []string
+	}{
+		{"analyst:db:events:", []string{"analyst", "db", "events", ""}},
+		{"analyst:db:users:id,name", []string{"analyst", "db", "users", "id,name"}},
+		{"ldap:analyst:*:*:", []string{"ldap:analyst", "*", "*", ""}},
+		{"analyst:db", nil},
+	}
+	for _, test := range tests {
+		if parts := splitGrantId(test.id); !reflect.DeepEqual(parts, test.expected) {
+			t.Errorf("splitGrantId(%q) = %q, expected %q", test.id, parts, test.expected)
+		}
+	}
+	grant := GrantResource{Grantee: "ldap:analyst", Database: "db", Table: "users", Columns: []string{"id"}}
+	if parts := splitGrantId(grantId(grant)); !reflect.DeepEqual(parts, []string{"ldap:analyst", "db", "users", "id"}) {
+		t.Errorf("splitGrantId(grantId()) = %q", parts)
+	}
+}
+
+func TestDeleteGrantOfMissingGrantee(t *testing.T) {
+	for _, code := range []int32{unknownUserCode, unknownRoleCode} {
+		fake := chfake.New()
+		fake.FailOn("REVOKE", &proto.Exception{Code: code, Message: "There is no grantee `analyst`"})
+		d := ResourceGrant().Data(nil)
+		for key, value := range map[string]any{"grantee": "analyst", "database": "db", "privileges": []any{"SELECT"}} {
+			if err := d.Set(key, value); err != nil {
+				t.Fatalf("setting %s failed: %v", key, err)
+			}
+		}
+		if diags := resourceGrantDelete(context.Background(), d, &common.ApiClient{ClickhouseConnection: fake}); diags.HasError() {
+			t.Errorf("deleting the grant of a missing grantee failed with code %d: %v", code, diags)
+		}
+	}
+
+	fake := chfake.New()
+	fake.FailOn("REVOKE", &proto.Exception{Code: 497, Message: "Not enough privileges"})
+	d := ResourceGrant().Data(nil)
+	if err := d.Set("privileges", []any{"SELECT"}); err != nil {
+		t.Fatalf("setting privileges failed: %v", err)
+	}
+	if diags := resourceGrantDelete(context.Background(), d, &common.ApiClient{ClickhouseConnection: fake}); !diags.HasError() {
+		t.Errorf("deleting the grant expected to fail without privileges")
+	}
+}
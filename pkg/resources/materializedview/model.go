@@ -0,0 +1,96 @@
+package resourcematerializedview
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+)
+
+const emptyUUID = "00000000-0000-0000-0000-000000000000"
+
+// tableNamePattern matches a table name, optionally qualified by its database, with quoted or unquoted identifiers.
+const tableNamePattern = "(?:`(?:[^`\\\\]|\\\\.)*`|\\w+)(?:\\.(?:`(?:[^`\\\\]|\\\\.)*`|\\w+))?"
+
+// toTableRegex extracts the target table of the views created with a TO clause from their create_table_query.
+// The TO clause is anchored right after the view name, so a TO found later, like the one of a TTL ... TO VOLUME
+// of the inner table or in the query, isn't taken for it.
+var toTableRegex = regexp.MustCompile("(?is)^CREATE\\s+MATERIALIZED\\s+VIEW\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?" + tableNamePattern +
+	"(?:\\s+UUID\\s+'[^']*')?(?:\\s+ON\\s+CLUSTER\\s+(?:'[^']*'|" + tableNamePattern + "))?\\s+TO\\s+(" + tableNamePattern + ")")
+
+type CHMaterializedView struct {
+	Database         string `ch:"database"`
+	Name             string `ch:"name"`
+	Comment          string `ch:"comment"`
+	AsSelect         string `ch:"as_select"`
+	CreateTableQuery string `ch:"create_table_query"`
+	UUID             string `ch:"uuid"`
+}
+
+type MaterializedViewResource struct {
+	Database string
+	Name     string
+	Cluster  string
+	Query    string
+	ToTable  string
+	Populate bool
+	Comment  string
+	Columns  []resourcetable.ColumnResource
+	// Storage holds the engine and storage clauses of the inner table, for the views without a TO table
+	Storage resourcetable.TableResource
+}
+
+func (v *CHMaterializedView) ToResource() *MaterializedViewResource {
+	comment, cluster := common.DecodeComment(v.Comment)
+
+	viewResource := MaterializedViewResource{
+		Database: v.Database,
+		Name:     v.Name,
+		Cluster:  cluster,
+		Query:    v.AsSelect,
+		Comment:  comment,
+	}
+	if matches := toTableRegex.FindStringSubmatch(v.CreateTableQuery); matches != nil {
+		viewResource.ToTable = strings.ReplaceAll(matches[1], "`", "")
+	}
+	return &viewResource
+}
+
+// InnerTableName returns the name of the table Clickhouse creates to store the rows of a view without a TO table.
+func (v *CHMaterializedView) InnerTableName() string {
+	if v.UUID != "" && v.UUID != emptyUUID {
+		return ".inner_id." + v.UUID
+	}
+	return ".inner." + v.Name
+}
+
+// KeepStateQuery keeps the query written in the configuration when it's equivalent to the one stored by
+// Clickhouse, which reformats it, so only actual changes to the view are reported as drift.
+func (v *MaterializedViewResource) KeepStateQuery(stateQuery string) {
+	if common.QueriesEquivalent(stateQuery, v.Query, v.Database) {
+		v.Query = stateQuery
+	}
+}
+
+// KeepStateToTable keeps the TO table written in the configuration when it references the same table,
+// e.g. events and db.events in the db database.
+func (v *MaterializedViewResource) KeepStateToTable(stateToTable string) {
+	if tableReferencesEqual(stateToTable, v.ToTable, v.Database) {
+		v.ToTable = stateToTable
+	}
+}
+
+func splitTableReference(reference string, database string) (string, string) {
+	reference = strings.ReplaceAll(reference, "`", "")
+	if separator := strings.Index(reference, "."); separator != -1 {
+		return reference[:separator], reference[separator+1:]
+	}
+	return database, reference
+}
+
+func tableReferencesEqual(a string, b string, database string) bool {
+	databaseA, tableA := splitTableReference(a, database)
+	databaseB, tableB := splitTableReference(b, database)
+	return databaseA == databaseB && tableA == tableB
+}
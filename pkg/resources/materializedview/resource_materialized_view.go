@@ -0,0 +1,359 @@
+package resourcematerializedview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceMaterializedView() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage materialized views, either writing into a TO table or storing their rows in an inner table",
+
+		CreateContext: resourceMaterializedViewCreate,
+		ReadContext:   resourceMaterializedViewRead,
+		UpdateContext: resourceMaterializedViewUpdate,
+		DeleteContext: resourceMaterializedViewDelete,
+		CustomizeDiff: resourceMaterializedViewCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceMaterializedViewImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"database": {
+				Description: "DB Name where the materialized view belongs",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Description: "Materialized view name",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"cluster": {
+				Description: "Cluster name, the provider default cluster is used when not provided",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"query": {
+				Description: "SELECT query of the view. Changes are applied with `ALTER TABLE ... MODIFY QUERY` on views with a `to_table`, older servers require the `allow_experimental_alter_materialized_view_structure` setting for it. Views with an inner engine are recreated",
+				Type:        schema.TypeString,
+				Required:    true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return common.QueriesEquivalent(old, new, d.Get("database").(string))
+				},
+			},
+			"to_table": {
+				Description:  "Table the view writes its rows to, as `table` or `database.table`. Either `to_table` or `engine` must be provided",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"to_table", "engine"},
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return old != "" && new != "" && tableReferencesEqual(old, new, d.Get("database").(string))
+				},
+			},
+			"engine": {
+				Description:  "Engine of the inner table storing the rows of the view, e.g. `SummingMergeTree`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"to_table", "engine"},
+			},
+			"engine_params": {
+				Description: "Engine params of the inner table",
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"order_by": {
+				Description: "Order by expressions of the inner table",
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"partition_by": {
+				Description: "Partition by expressions of the inner table, e.g. `toYYYYMM(event_date)`",
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"primary_key": {
+				Description: "Primary key expressions of the inner table, the order by ones are used when not provided",
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"settings": {
+				Description: "Settings of the inner table, e.g. `{ index_granularity = \"8192\" }`",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"populate": {
+				Description:   "Fill the inner table with the rows already existing in the source table when the view is created. Rows inserted while populating are lost, and it can't be used along with `to_table`",
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ForceNew:      true,
+				ConflictsWith: []string{"to_table"},
+			},
+			"column": {
+				Description: "Columns of the view, inferred from the query when not provided",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Column name",
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+						},
+						"type": {
+							Description: "Column type",
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+						},
+					},
+				},
+			},
+			"comment": {
+				Description: "Materialized view comment, it will be codified in a json along with some metadata information (like cluster name in case of clustering)",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+		},
+	}
+}
+
+func getMaterializedViewResource(d *schema.ResourceData, defaultCluster string) MaterializedViewResource {
+	viewResource := MaterializedViewResource{
+		Database: d.Get("database").(string),
+		Name:     d.Get("name").(string),
+		Cluster:  d.Get("cluster").(string),
+		Query:    d.Get("query").(string),
+		ToTable:  d.Get("to_table").(string),
+		Populate: d.Get("populate").(bool),
+		Comment:  d.Get("comment").(string),
+		Storage:  getStorage(d),
+	}
+	if viewResource.Cluster == "" {
+		viewResource.Cluster = defaultCluster
+	}
+	for _, column := range d.Get("column").([]interface{}) {
+		columnMap := column.(map[string]interface{})
+		viewResource.Columns = append(viewResource.Columns, resourcetable.ColumnResource{
+			Name: columnMap["name"].(string),
+			Type: columnMap["type"].(string),
+		})
+	}
+	return viewResource
+}
+
+func getStorage(d *schema.ResourceData) resourcetable.TableResource {
+	storage := resourcetable.TableResource{
+		Engine:       d.Get("engine").(string),
+		EngineParams: common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{})),
+		OrderBy:      common.MapArrayInterfaceToArrayOfStrings(d.Get("order_by").([]interface{})),
+		PrimaryKey:   common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{})),
+	}
+	for _, partitionBy := range common.MapArrayInterfaceToArrayOfStrings(d.Get("partition_by").([]interface{})) {
+		storage.PartitionBy = append(storage.PartitionBy, resourcetable.PartitionByResource{By: partitionBy})
+	}
+	storage.SetSettings(d.Get("settings").(map[string]interface{}))
+	return storage
+}
+
+func resourceMaterializedViewCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	// Views storing their rows in an inner table are recreated, the query may change the structure of the table
+	if d.Id() != "" && d.HasChange("query") && d.Get("to_table").(string) == "" {
+		return d.ForceNew("query")
+	}
+	return nil
+}
+
+func resourceMaterializedViewRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHMaterializedViewService{CHConnection: client.ClickhouseConnection}
+
+	database := d.Get("database").(string)
+	viewName := d.Get("name").(string)
+
+	chView, err := chViewService.GetMaterializedView(ctx, database, viewName)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse materialized view: %v", err))
+	}
+	if chView == nil {
+		tflog.Warn(ctx, "Materialized view not found, removing it from state", map[string]interface{}{"database": database, "view": viewName})
+		d.SetId("")
+		return diags
+	}
+
+	viewResource := chView.ToResource()
+	viewResource.KeepStateQuery(d.Get("query").(string))
+	viewResource.KeepStateToTable(d.Get("to_table").(string))
+	viewResource.Cluster = common.KnownCluster(viewResource.Cluster, d.Get("cluster").(string))
+
+	viewTable, innerTable, err := chViewService.GetStorage(ctx, chView)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse materialized view storage: %v", err))
+	}
+
+	if err := d.Set("database", viewResource.Database); err != nil {
+		return diag.FromErr(fmt.Errorf("setting database: %v", err))
+	}
+	if err := d.Set("name", viewResource.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %v", err))
+	}
+	if err := d.Set("cluster", viewResource.Cluster); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster: %v", err))
+	}
+	if err := d.Set("query", viewResource.Query); err != nil {
+		return diag.FromErr(fmt.Errorf("setting query: %v", err))
+	}
+	if err := d.Set("to_table", viewResource.ToTable); err != nil {
+		return diag.FromErr(fmt.Errorf("setting to_table: %v", err))
+	}
+	if err := d.Set("comment", viewResource.Comment); err != nil {
+		return diag.FromErr(fmt.Errorf("setting comment: %v", err))
+	}
+
+	if viewTable != nil {
+		var columns []map[string]interface{}
+		for _, column := range viewTable.Columns {
+			columns = append(columns, map[string]interface{}{"name": column.Name, "type": column.Type})
+		}
+		if err := d.Set("column", columns); err != nil {
+			return diag.FromErr(fmt.Errorf("setting column: %v", err))
+		}
+	}
+
+	if innerTable != nil {
+		storage, err := innerTable.ToResource()
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("transforming materialized view inner table to resource: %v", err))
+		}
+		stateStorage := getStorage(d)
+		storage.EngineParams = resourcetable.NormalizeEngineParams(storage.Engine, stateStorage.EngineParams, storage.EngineParams)
+		storage.KeepStateKeys(&stateStorage)
+
+		if err := d.Set("engine", storage.Engine); err != nil {
+			return diag.FromErr(fmt.Errorf("setting engine: %v", err))
+		}
+		if err := d.Set("engine_params", storage.EngineParams); err != nil {
+			return diag.FromErr(fmt.Errorf("setting engine_params: %v", err))
+		}
+		if err := d.Set("order_by", storage.OrderBy); err != nil {
+			return diag.FromErr(fmt.Errorf("setting order_by: %v", err))
+		}
+		if err := d.Set("partition_by", storage.PartitionByExpressions()); err != nil {
+			return diag.FromErr(fmt.Errorf("setting partition_by: %v", err))
+		}
+		if err := d.Set("primary_key", storage.PrimaryKey); err != nil {
+			return diag.FromErr(fmt.Errorf("setting primary_key: %v", err))
+		}
+		if err := d.Set("settings", storage.Settings); err != nil {
+			return diag.FromErr(fmt.Errorf("setting settings: %v", err))
+		}
+	}
+
+	d.SetId(viewResource.Cluster + ":" + database + ":" + viewName)
+
+	return diags
+}
+
+func resourceMaterializedViewImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Same ID format written by resourceMaterializedViewCreate, the cluster part may be empty: <cluster>:<database>:<view>
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) < 3 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return nil, fmt.Errorf("unexpected import id %q, expected <cluster>:<database>:<view>", d.Id())
+	}
+
+	if err := d.Set("cluster", strings.Join(parts[:len(parts)-2], ":")); err != nil {
+		return nil, fmt.Errorf("setting cluster: %v", err)
+	}
+	if err := d.Set("database", parts[len(parts)-2]); err != nil {
+		return nil, fmt.Errorf("setting database: %v", err)
+	}
+	if err := d.Set("name", parts[len(parts)-1]); err != nil {
+		return nil, fmt.Errorf("setting name: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceMaterializedViewCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHMaterializedViewService{CHConnection: client.ClickhouseConnection}
+	viewResource := getMaterializedViewResource(d, client.DefaultCluster)
+
+	if err := chViewService.CreateMaterializedView(ctx, viewResource); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(viewResource.Cluster + ":" + viewResource.Database + ":" + viewResource.Name)
+
+	return diags
+}
+
+func resourceMaterializedViewUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHMaterializedViewService{CHConnection: client.ClickhouseConnection}
+	viewResource := getMaterializedViewResource(d, client.DefaultCluster)
+
+	if d.HasChange("query") {
+		if err := chViewService.AlterMaterializedViewQuery(ctx, viewResource); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("comment") {
+		if err := chViewService.AlterMaterializedViewComment(ctx, viewResource); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
+func resourceMaterializedViewDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHMaterializedViewService{CHConnection: client.ClickhouseConnection}
+
+	if err := chViewService.DeleteMaterializedView(ctx, getMaterializedViewResource(d, client.DefaultCluster)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
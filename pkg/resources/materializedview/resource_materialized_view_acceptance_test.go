@@ -0,0 +1,153 @@
+package resourcematerializedview_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testResourceMaterializedViewDatabaseName = "materialized_view_test_database"
+
+func TestAccResourceMaterializedView_ToTable(t *testing.T) {
+	const viewName = "daily_events_mv"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: toTableConfig(testResourceMaterializedViewDatabaseName, viewName, "select toDate(eventTime) as day, count() as events\n\t\tfrom events\n\t\tgroup by day", "daily events"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "name", viewName),
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "to_table", "daily_events"),
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "column.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "comment", "daily events"),
+				),
+			},
+			// REFORMATTED QUERY DOESN'T CHANGE THE VIEW
+			{
+				Config:   toTableConfig(testResourceMaterializedViewDatabaseName, viewName, "SELECT toDate(eventTime) AS day, count() AS events FROM events GROUP BY day", "daily events"),
+				PlanOnly: true,
+			},
+			// QUERY AND COMMENT ARE ALTERED IN PLACE
+			{
+				Config: toTableConfig(testResourceMaterializedViewDatabaseName, viewName, "SELECT toDate(eventTime) AS day, uniq(key) AS events FROM events GROUP BY day", "unique daily events"),
+				Check:  resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "comment", "unique daily events"),
+			},
+			{
+				ResourceName:            "clickhouse_materialized_view.view",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"query", "populate"},
+			},
+		},
+	})
+}
+
+func TestAccResourceMaterializedView_InnerEngine(t *testing.T) {
+	const viewName = "daily_events_inner_mv"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: innerEngineConfig(testResourceMaterializedViewDatabaseName, viewName, "SELECT toDate(eventTime) AS day, count() AS events FROM events GROUP BY day"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "engine", "SummingMergeTree"),
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "order_by.0", "day"),
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "partition_by.0", "toYYYYMM(day)"),
+					resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "populate", "true"),
+				),
+			},
+			// INNER ENGINE VIEWS ARE RECREATED WHEN THE QUERY CHANGES
+			{
+				Config: innerEngineConfig(testResourceMaterializedViewDatabaseName, viewName, "SELECT toDate(eventTime) AS day, uniq(key) AS events FROM events GROUP BY day"),
+				Check:  resource.TestCheckResourceAttr("clickhouse_materialized_view.view", "engine", "SummingMergeTree"),
+			},
+			{
+				ResourceName:            "clickhouse_materialized_view.view",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"query", "populate"},
+			},
+		},
+	})
+}
+
+const eventsTableConfig = `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+	}
+
+	resource "clickhouse_table" "events" {
+		database = clickhouse_db.new_db_resource.name
+		name = "events"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "Int64"
+		}
+		column {
+			name = "eventTime"
+			type = "DateTime"
+		}
+	}
+`
+
+func toTableConfig(database string, viewName string, query string, comment string) string {
+	s := eventsTableConfig + `
+	resource "clickhouse_table" "daily_events" {
+		database = clickhouse_db.new_db_resource.name
+		name = "daily_events"
+		engine = "SummingMergeTree"
+		order_by = ["day"]
+		column {
+			name = "day"
+			type = "Date"
+		}
+		column {
+			name = "events"
+			type = "UInt64"
+		}
+	}
+
+	resource "clickhouse_materialized_view" "view" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_viewName_%"
+		to_table = clickhouse_table.daily_events.name
+		query = <<-EOT
+		%_query_%
+		EOT
+		comment = "%_comment_%"
+
+		depends_on = [clickhouse_table.events]
+	}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_viewName_%", viewName, -1)
+	s = strings.Replace(s, "%_query_%", query, -1)
+	s = strings.Replace(s, "%_comment_%", comment, -1)
+	return s
+}
+
+func innerEngineConfig(database string, viewName string, query string) string {
+	s := eventsTableConfig + `
+	resource "clickhouse_materialized_view" "view" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_viewName_%"
+		engine = "SummingMergeTree"
+		order_by = ["day"]
+		partition_by = ["toYYYYMM(day)"]
+		populate = true
+		query = "%_query_%"
+
+		depends_on = [clickhouse_table.events]
+	}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_viewName_%", viewName, -1)
+	s = strings.Replace(s, "%_query_%", query, -1)
+	return s
+}
@@ -0,0 +1,146 @@
+package resourcematerializedview
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+)
+
+const materializedViewEngine = "MaterializedView"
+
+type CHMaterializedViewService struct {
+	CHConnection common.Executor
+}
+
+func (vs *CHMaterializedViewService) GetMaterializedView(ctx context.Context, database string, name string) (*CHMaterializedView, error) {
+	query := "SELECT database, name, comment, as_select, create_table_query, toString(uuid) AS uuid FROM system.tables WHERE database = ? AND name = ? AND engine = ?"
+	row := vs.CHConnection.QueryRow(ctx, query, database, name, materializedViewEngine)
+
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading materialized view from Clickhouse: %v", row.Err())
+	}
+
+	var chView CHMaterializedView
+	err := row.ScanStruct(&chView)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse materialized view row: %v", err)
+	}
+	return &chView, nil
+}
+
+func (vs *CHMaterializedViewService) GetDBMaterializedViews(ctx context.Context, database string) ([]CHMaterializedView, error) {
+	query := "SELECT database, name FROM system.tables WHERE database = ? AND engine = ?"
+	rows, err := vs.CHConnection.Query(ctx, query, database, materializedViewEngine)
+
+	if err != nil {
+		return nil, fmt.Errorf("reading materialized views from Clickhouse: %v", err)
+	}
+
+	var views []CHMaterializedView
+	for rows.Next() {
+		var view CHMaterializedView
+		err := rows.ScanStruct(&view)
+		if err != nil {
+			return nil, fmt.Errorf("scanning Clickhouse materialized view row: %v", err)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// GetStorage returns the columns of a view and, for views without a TO table, the engine of their inner table.
+func (vs *CHMaterializedViewService) GetStorage(ctx context.Context, chView *CHMaterializedView) (*resourcetable.CHTable, *resourcetable.CHTable, error) {
+	chTableService := resourcetable.CHTableService{CHConnection: vs.CHConnection}
+
+	viewTable, err := chTableService.GetTable(ctx, chView.Database, chView.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading materialized view columns: %v", err)
+	}
+	if chView.ToResource().ToTable != "" {
+		return viewTable, nil, nil
+	}
+
+	innerTable, err := chTableService.GetTable(ctx, chView.Database, chView.InnerTableName())
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading materialized view inner table: %v", err)
+	}
+	return viewTable, innerTable, nil
+}
+
+func (vs *CHMaterializedViewService) CreateMaterializedView(ctx context.Context, viewResource MaterializedViewResource) error {
+	err := vs.CHConnection.Exec(ctx, buildCreateMaterializedViewQuery(viewResource))
+	if err != nil {
+		return fmt.Errorf("creating Clickhouse materialized view: %v", err)
+	}
+	return nil
+}
+
+func (vs *CHMaterializedViewService) AlterMaterializedViewQuery(ctx context.Context, viewResource MaterializedViewResource) error {
+	action := fmt.Sprintf("MODIFY QUERY %s", common.TrimQuery(viewResource.Query))
+	err := vs.CHConnection.Exec(ctx, buildAlterMaterializedViewQuery(viewResource, action))
+	if err != nil {
+		return fmt.Errorf("altering Clickhouse materialized view query: %v", err)
+	}
+	return nil
+}
+
+func (vs *CHMaterializedViewService) AlterMaterializedViewComment(ctx context.Context, viewResource MaterializedViewResource) error {
+	action := fmt.Sprintf("MODIFY COMMENT %s", common.QuoteString(common.GetComment(viewResource.Comment, viewResource.Cluster)))
+	err := vs.CHConnection.Exec(ctx, buildAlterMaterializedViewQuery(viewResource, action))
+	if err != nil {
+		return fmt.Errorf("altering Clickhouse materialized view comment: %v", err)
+	}
+	return nil
+}
+
+func (vs *CHMaterializedViewService) DeleteMaterializedView(ctx context.Context, viewResource MaterializedViewResource) error {
+	query := fmt.Sprintf("DROP VIEW %s %s", common.QualifiedName(viewResource.Database, viewResource.Name), common.GetClusterStatement(viewResource.Cluster))
+	err := vs.CHConnection.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("deleting Clickhouse materialized view: %v", err)
+	}
+	return nil
+}
+
+func buildCreateMaterializedViewQuery(viewResource MaterializedViewResource) string {
+	var clauses []string
+	if viewResource.ToTable != "" {
+		toDatabase, toTable := splitTableReference(viewResource.ToTable, viewResource.Database)
+		clauses = append(clauses, fmt.Sprintf("TO %s", common.QualifiedName(toDatabase, toTable)))
+	}
+	if len(viewResource.Columns) > 0 {
+		clauses = append(clauses, resourcetable.BuildColumnsStatement(viewResource.Columns))
+	}
+	if viewResource.ToTable == "" {
+		clauses = append(clauses, resourcetable.BuildEngineSentence(viewResource.Storage))
+	}
+	if viewResource.Populate {
+		clauses = append(clauses, "POPULATE")
+	}
+
+	return fmt.Sprintf(
+		"CREATE MATERIALIZED VIEW %s %s %s AS %sCOMMENT %s",
+		common.QualifiedName(viewResource.Database, viewResource.Name),
+		common.GetClusterStatement(viewResource.Cluster),
+		strings.Join(clauses, " "),
+		common.TrimQuery(viewResource.Query),
+		common.QuoteString(common.GetComment(viewResource.Comment, viewResource.Cluster)),
+	)
+}
+
+func buildAlterMaterializedViewQuery(viewResource MaterializedViewResource, action string) string {
+	return fmt.Sprintf(
+		"ALTER TABLE %s %s %s",
+		common.QualifiedName(viewResource.Database, viewResource.Name),
+		common.GetClusterStatement(viewResource.Cluster),
+		action,
+	)
+}
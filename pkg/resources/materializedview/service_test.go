@@ -0,0 +1,160 @@
+package resourcematerializedview
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/resources/table"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+)
+
+func TestGetMaterializedView(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.tables",
+		chfake.Row{"database": "db", "name": "events", "engine": "MergeTree"},
+		chfake.Row{
+			"database":           "db",
+			"name":               "events_mv",
+			"engine":             "MaterializedView",
+			"comment":            `{"provider":"terraform-provider-clickhouse","version":1,"comment":"daily","cluster":"cluster"}`,
+			"as_select":          "SELECT toDate(ts) AS day, count() AS events FROM db.events GROUP BY day",
+			"create_table_query": "CREATE MATERIALIZED VIEW db.events_mv TO `other`.`daily_events` (`day` Date, `events` UInt64) AS SELECT toDate(ts) AS day, count() AS events FROM db.events GROUP BY day",
+			"uuid":               "4c9d8b1e-0f6a-4a1b-9a43-3e1f3c3e9b1a",
+		},
+		chfake.Row{
+			"database":           "db",
+			"name":               "inner_mv",
+			"engine":             "MaterializedView",
+			"comment":            "created by hand",
+			"as_select":          "SELECT day, count() AS events FROM db.events GROUP BY day",
+			"create_table_query": "CREATE MATERIALIZED VIEW db.inner_mv (`day` Date, `events` UInt64) ENGINE = SummingMergeTree ORDER BY day TTL day + toIntervalMonth(1) TO VOLUME 'cold' AS SELECT day, count() AS events FROM db.events GROUP BY day",
+			"uuid":               "00000000-0000-0000-0000-000000000000",
+		},
+	)
+	service := CHMaterializedViewService{CHConnection: fake}
+
+	view, err := service.GetMaterializedView(context.Background(), "db", "events_mv")
+	if err != nil {
+		t.Fatalf("GetMaterializedView failed: %v", err)
+	}
+	viewResource := view.ToResource()
+	viewResource.KeepStateQuery("select toDate(ts) as day, count() as events\nfrom events\ngroup by day")
+	viewResource.KeepStateToTable("other.daily_events")
+	expected := MaterializedViewResource{
+		Database: "db",
+		Name:     "events_mv",
+		Cluster:  "cluster",
+		Query:    "select toDate(ts) as day, count() as events\nfrom events\ngroup by day",
+		ToTable:  "other.daily_events",
+		Comment:  "daily",
+	}
+	if !reflect.DeepEqual(*viewResource, expected) {
+		t.Errorf("GetMaterializedView() = %+v, expected %+v", *viewResource, expected)
+	}
+	if name := view.InnerTableName(); name != ".inner_id.4c9d8b1e-0f6a-4a1b-9a43-3e1f3c3e9b1a" {
+		t.Errorf("InnerTableName() = %q", name)
+	}
+
+	view, err = service.GetMaterializedView(context.Background(), "db", "inner_mv")
+	if err != nil {
+		t.Fatalf("GetMaterializedView failed: %v", err)
+	}
+	viewResource = view.ToResource()
+	if viewResource.ToTable != "" || viewResource.Comment != "created by hand" || viewResource.Cluster != "" {
+		t.Errorf("unexpected materialized view %+v", *viewResource)
+	}
+	if name := view.InnerTableName(); name != ".inner.inner_mv" {
+		t.Errorf("InnerTableName() = %q", name)
+	}
+
+	for _, name := range []string{"events", "missing"} {
+		missing, err := service.GetMaterializedView(context.Background(), "db", name)
+		if err != nil || missing != nil {
+			t.Errorf("GetMaterializedView(%q) = %v, %v, expected nil", name, missing, err)
+		}
+	}
+}
+
+func TestToTableRegex(t *testing.T) {
+	tests := map[string]string{
+		"CREATE MATERIALIZED VIEW db.mv TO db.target (`a` UInt8) AS SELECT a FROM db.source":                                    "db.target",
+		"CREATE MATERIALIZED VIEW `my db`.`my mv` TO `my db`.`my target` AS SELECT a FROM db.source":                            "`my db`.`my target`",
+		"CREATE MATERIALIZED VIEW db.mv (`a` UInt8) ENGINE = MergeTree ORDER BY a TTL a TO VOLUME 'cold' AS SELECT a FROM db.t": "",
+		"CREATE MATERIALIZED VIEW db.mv (`a` UInt8) ENGINE = Memory AS SELECT a AS to FROM db.t":                                "",
+	}
+
+	for query, expected := range tests {
+		toTable := ""
+		if matches := toTableRegex.FindStringSubmatch(query); matches != nil {
+			toTable = matches[1]
+		}
+		if toTable != expected {
+			t.Errorf("TO table of %q = %q, expected %q", query, toTable, expected)
+		}
+	}
+}
+
+func TestTableReferencesEqual(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"events", "db.events", true},
+		{"`db`.`events`", "events", true},
+		{"other.events", "events", false},
+		{"events", "daily_events", false},
+	}
+	for _, test := range tests {
+		if actual := tableReferencesEqual(test.a, test.b, "db"); actual != test.expected {
+			t.Errorf("tableReferencesEqual(%q, %q) = %v, expected %v", test.a, test.b, actual, test.expected)
+		}
+	}
+}
+
+func TestMaterializedViewStatements(t *testing.T) {
+	fake := chfake.New()
+	service := CHMaterializedViewService{CHConnection: fake}
+	ctx := context.Background()
+
+	views := []MaterializedViewResource{
+		{
+			Database: "db",
+			Name:     "events_mv",
+			Query:    "SELECT toDate(ts) AS day, count() AS events FROM events GROUP BY day;",
+			ToTable:  "daily_events",
+			Comment:  "daily",
+		},
+		{
+			Database: "db",
+			Name:     "inner_mv",
+			Cluster:  "cluster",
+			Query:    "SELECT toDate(ts) AS day, count() AS events FROM events GROUP BY day -- per day",
+			Populate: true,
+			Columns:  []resourcetable.ColumnResource{{Name: "day", Type: "Date"}, {Name: "events", Type: "UInt64"}},
+			Storage: resourcetable.TableResource{
+				Engine:      "SummingMergeTree",
+				OrderBy:     []string{"day"},
+				PartitionBy: []resourcetable.PartitionByResource{{By: "toYYYYMM(day)"}},
+				Settings:    map[string]string{"index_granularity": "8192"},
+			},
+		},
+	}
+	for _, view := range views {
+		if err := service.CreateMaterializedView(ctx, view); err != nil {
+			t.Fatalf("CreateMaterializedView failed: %v", err)
+		}
+		if err := service.AlterMaterializedViewQuery(ctx, view); err != nil {
+			t.Fatalf("AlterMaterializedViewQuery failed: %v", err)
+		}
+		if err := service.AlterMaterializedViewComment(ctx, view); err != nil {
+			t.Fatalf("AlterMaterializedViewComment failed: %v", err)
+		}
+		if err := service.DeleteMaterializedView(ctx, view); err != nil {
+			t.Fatalf("DeleteMaterializedView failed: %v", err)
+		}
+	}
+	golden.Assert(t, "materialized_view_statements", strings.Join(fake.Statements, "\n"))
+}
@@ -2,14 +2,19 @@ package resourcerole
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 type CHGrant struct {
-	RoleName   string `ch:"role_name"`
-	AccessType string `ch:"access_type"`
-	Database   string `ch:"database"`
+	RoleName        string `ch:"role_name"`
+	AccessType      string `ch:"access_type"`
+	Database        string `ch:"database"`
+	Table           string `ch:"table"`
+	Column          string `ch:"column"`
+	IsPartialRevoke uint8  `ch:"is_partial_revoke"`
 }
 
 type CHRole struct {
@@ -23,24 +28,40 @@ type RoleResource struct {
 	Privileges *schema.Set
 }
 
-func (r *CHRole) ToRoleResource() (*RoleResource, error) {
-	var database string
-	var privileges []string
-	for i := 0; i < len(r.Privileges); i++ {
-		if database != "" && r.Privileges[i].Database != "" && r.Privileges[i].Database != database {
-			return nil, fmt.Errorf("role %s has privileges on different databases", r.Name)
+// ToRoleResource returns the role as managed on database with privileges, usually the ones of the state.
+// Only those privileges granted on database are reported, so the ones granted to the role by other resources,
+// like a clickhouse_grant on the same or another database, aren't taken for drift. Every privilege is reported
+// when database is empty, e.g. on import, the role must then have privileges on a single database.
+func (r *CHRole) ToRoleResource(database string, privileges []string) (*RoleResource, error) {
+	if database == "" {
+		var roleDatabase string
+		var rolePrivileges []string
+		for _, privilege := range r.Privileges {
+			if roleDatabase != "" && privilege.Database != roleDatabase {
+				return nil, fmt.Errorf("role %s has privileges on different databases", r.Name)
+			}
+			roleDatabase = privilege.Database
+			rolePrivileges = append(rolePrivileges, privilege.AccessType)
 		}
-		database = r.Privileges[i].Database
-		privileges = append(privileges, r.Privileges[i].AccessType)
+		return &RoleResource{Name: r.Name, Database: roleDatabase, Privileges: common.StringListToSet(rolePrivileges)}, nil
 	}
 
-	return &RoleResource{Name: r.Name, Database: database, Privileges: common.StringListToSet(privileges)}, nil
-}
-
-func (r *CHRole) GetPrivilegesList() []string {
-	var privileges []string
+	// Privileges are matched case insensitively, keeping the spelling of the state
+	managed := make(map[string]string)
+	for _, privilege := range privileges {
+		managed[normalizePrivilege(privilege)] = privilege
+	}
+	var rolePrivileges []string
 	for _, privilege := range r.Privileges {
-		privileges = append(privileges, privilege.AccessType)
+		if managedPrivilege, ok := managed[normalizePrivilege(privilege.AccessType)]; ok && privilege.Database == database {
+			rolePrivileges = append(rolePrivileges, managedPrivilege)
+		}
 	}
-	return privileges
+	return &RoleResource{Name: r.Name, Database: database, Privileges: common.StringListToSet(rolePrivileges)}, nil
+}
+
+// normalizePrivilege returns the form privileges are compared in, as Clickhouse keywords are case insensitive,
+// e.g. "show  tables" is "SHOW TABLES".
+func normalizePrivilege(privilege string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(privilege), " "))
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -15,6 +16,14 @@ func ResourceRole() *schema.Resource {
 		ReadContext:   resourceRoleRead,
 		DeleteContext: resourceRoleDelete,
 		UpdateContext: resourceRoleUpdate,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRoleImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Description: "Role name",
@@ -78,8 +87,14 @@ func resourceRoleRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("resource role read: %v", err))
 	}
+	if chRole == nil {
+		tflog.Warn(ctx, "Role not found, removing it from state", map[string]interface{}{"name": roleNameState})
+		d.SetId("")
+		return diags
+	}
 
-	roleResource, err := chRole.ToRoleResource()
+	statePrivileges := common.StringSetToList(d.Get("privileges").(*schema.Set))
+	roleResource, err := chRole.ToRoleResource(d.Get("database").(string), statePrivileges)
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("resource role read: %v", err))
 	}
@@ -99,6 +114,14 @@ func resourceRoleRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 	return diags
 }
 
+func resourceRoleImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Roles are imported by name, database and privileges are rebuilt from system.grants on read
+	if err := d.Set("name", d.Id()); err != nil {
+		return nil, fmt.Errorf("resource role import: %v", err)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceRoleCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := meta.(*common.ApiClient)
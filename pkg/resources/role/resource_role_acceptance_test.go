@@ -195,6 +195,16 @@ func TestAccResourceRole(t *testing.T) {
 		CheckDestroy: testAccCheckRoleResourceDestroy([]string{roleName1, roleName2}),
 		Steps:        generateTestSteps(test1StepsData),
 	})
+	// Import
+	resource.Test(t, resource.TestCase{
+		Providers:    testutils.Provider(),
+		CheckDestroy: testAccCheckRoleResourceDestroy([]string{roleName1}),
+		Steps: append(generateTestSteps(test1StepsData[:1]), resource.TestStep{
+			ResourceName:      roleResource,
+			ImportState:       true,
+			ImportStateVerify: true,
+		}),
+	})
 	// Feature tests, system database
 	resource.Test(t, resource.TestCase{
 		Providers:    testutils.Provider(),
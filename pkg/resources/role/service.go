@@ -3,25 +3,35 @@ package resourcerole
 import (
 	"context"
 	"fmt"
-	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"strings"
 )
 
 type CHRoleService struct {
-	CHConnection *driver.Conn
+	CHConnection common.Executor
+}
+
+// quoteDatabase quotes the database of a grant, the * wildcard standing for every database is kept as is.
+func quoteDatabase(database string) string {
+	if database == "*" {
+		return database
+	}
+	return common.QuoteIdentifier(database)
 }
 
 func getGrantQuery(roleName string, privileges []string, database string) string {
 	if database == "system" || database == "*" {
-		return fmt.Sprintf("GRANT CURRENT GRANTS (%s ON %s.*) TO %s", strings.Join(privileges, ","), database, roleName)
+		return fmt.Sprintf("GRANT CURRENT GRANTS (%s ON %s.*) TO %s", strings.Join(privileges, ","), quoteDatabase(database), common.QuoteIdentifier(roleName))
 	}
-	return fmt.Sprintf("GRANT %s ON %s.* TO %s", strings.Join(privileges, ","), database, roleName)
+	return fmt.Sprintf("GRANT %s ON %s.* TO %s", strings.Join(privileges, ","), quoteDatabase(database), common.QuoteIdentifier(roleName))
 }
 
+// getRoleGrants returns the privileges granted to the role at database level, the ones granted on tables or
+// columns, e.g. by a clickhouse_grant, and partial revokes are left out.
 func (rs *CHRoleService) getRoleGrants(ctx context.Context, roleName string) ([]CHGrant, error) {
-	query := fmt.Sprintf("SELECT role_name, access_type, database FROM system.grants WHERE role_name = '%s'", roleName)
-	rows, err := (*rs.CHConnection).Query(ctx, query)
+	query := "SELECT role_name, access_type, database, table, column, is_partial_revoke FROM system.grants WHERE role_name = ?"
+	rows, err := rs.CHConnection.Query(ctx, query, roleName)
 
 	if err != nil {
 		return nil, fmt.Errorf("error fetching role grants: %s", err)
@@ -34,6 +44,9 @@ func (rs *CHRoleService) getRoleGrants(ctx context.Context, roleName string) ([]
 		if err != nil {
 			return nil, fmt.Errorf("error scanning role grant: %s", err)
 		}
+		if privilege.Table != "" || privilege.Column != "" || privilege.IsPartialRevoke != 0 {
+			continue
+		}
 		if privilege.Database == "" {
 			privilege.Database = "*"
 		}
@@ -44,9 +57,9 @@ func (rs *CHRoleService) getRoleGrants(ctx context.Context, roleName string) ([]
 }
 
 func (rs *CHRoleService) GetRole(ctx context.Context, roleName string) (*CHRole, error) {
-	roleQuery := fmt.Sprintf("SELECT name FROM system.roles WHERE name = '%s'", roleName)
+	roleQuery := "SELECT name FROM system.roles WHERE name = ?"
 
-	rows, err := (*rs.CHConnection).Query(ctx, roleQuery)
+	rows, err := rs.CHConnection.Query(ctx, roleQuery, roleName)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching role: %s", err)
 	}
@@ -76,54 +89,49 @@ func (rs *CHRoleService) UpdateRole(ctx context.Context, rolePlan RoleResource,
 		return nil, fmt.Errorf("role %s not found", rolePlan.Name)
 	}
 
+	// Only the privileges of the state are revoked, the role may have others granted by other resources
+	stateDatabase, _ := resourceData.GetChange("database")
+	statePrivileges, _ := resourceData.GetChange("privileges")
+	roleState, err := chRole.ToRoleResource(stateDatabase.(string), common.StringSetToList(statePrivileges.(*schema.Set)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading role %s: %v", chRole.Name, err)
+	}
+	currentPrivileges := roleState.Privileges
+
 	roleNameHasChange := resourceData.HasChange("name")
 	roleDatabaseHasChange := resourceData.HasChange("database")
-	rolePrivilegesHasChange := resourceData.HasChange("privileges")
 
 	var grantPrivileges []string
 	var revokePrivileges []string
-	if rolePrivilegesHasChange {
-		for _, planPrivilege := range rolePlan.Privileges.List() {
-			found := false
-			for _, privilege := range chRole.Privileges {
-				if privilege.AccessType == planPrivilege {
-					found = true
-				}
-			}
-			if found == false {
-				grantPrivileges = append(grantPrivileges, planPrivilege.(string))
+	if roleDatabaseHasChange {
+		// Privileges are moved to the new database
+		grantPrivileges = common.StringSetToList(rolePlan.Privileges)
+	} else {
+		for _, planPrivilege := range common.StringSetToList(rolePlan.Privileges) {
+			if !currentPrivileges.Contains(planPrivilege) {
+				grantPrivileges = append(grantPrivileges, planPrivilege)
 			}
 		}
-
-		for _, privilege := range chRole.Privileges {
-			if rolePlan.Privileges.Contains(privilege.AccessType) == false {
-				revokePrivileges = append(revokePrivileges, privilege.AccessType)
+		for _, privilege := range common.StringSetToList(currentPrivileges) {
+			if !rolePlan.Privileges.Contains(privilege) {
+				revokePrivileges = append(revokePrivileges, privilege)
 			}
 		}
 	}
 
-	conn := *rs.CHConnection
+	conn := rs.CHConnection
 
 	if roleNameHasChange {
-		err := conn.Exec(ctx, fmt.Sprintf("ALTER ROLE %s RENAME TO %s", chRole.Name, rolePlan.Name))
+		err := conn.Exec(ctx, fmt.Sprintf("ALTER ROLE %s RENAME TO %s", common.QuoteIdentifier(chRole.Name), common.QuoteIdentifier(rolePlan.Name)))
 		if err != nil {
 			return nil, fmt.Errorf("error renaming role %s to %s: %v", chRole.Name, rolePlan.Name, err)
 		}
 	}
 
-	if roleDatabaseHasChange {
-		err := conn.Exec(ctx, fmt.Sprintf("REVOKE ALL ON *.* FROM %s", rolePlan.Name))
+	if roleDatabaseHasChange && currentPrivileges.Len() > 0 {
+		err := conn.Exec(ctx, fmt.Sprintf("REVOKE %s ON %s.* FROM %s", strings.Join(common.StringSetToList(currentPrivileges), ","), quoteDatabase(roleState.Database), common.QuoteIdentifier(rolePlan.Name)))
 		if err != nil {
-			return nil, fmt.Errorf("error revoking all privileges from role %s: %v", chRole.Name, err)
-		}
-		dbPrivileges := chRole.GetPrivilegesList()
-		err = conn.Exec(ctx, getGrantQuery(
-			rolePlan.Name,
-			dbPrivileges,
-			rolePlan.Database,
-		))
-		if err != nil {
-			return nil, fmt.Errorf("error granting privileges to role %s: %v", chRole.Name, err)
+			return nil, fmt.Errorf("error revoking privileges from role %s: %v", chRole.Name, err)
 		}
 	}
 
@@ -135,7 +143,7 @@ func (rs *CHRoleService) UpdateRole(ctx context.Context, rolePlan RoleResource,
 	}
 
 	if len(revokePrivileges) > 0 {
-		err := conn.Exec(ctx, fmt.Sprintf("REVOKE %s ON %s.* FROM %s", strings.Join(revokePrivileges, ","), rolePlan.Database, rolePlan.Name))
+		err := conn.Exec(ctx, fmt.Sprintf("REVOKE %s ON %s.* FROM %s", strings.Join(revokePrivileges, ","), quoteDatabase(rolePlan.Database), common.QuoteIdentifier(rolePlan.Name)))
 		if err != nil {
 			return nil, fmt.Errorf("error revoking privileges from role %s: %v", chRole.Name, err)
 		}
@@ -145,8 +153,8 @@ func (rs *CHRoleService) UpdateRole(ctx context.Context, rolePlan RoleResource,
 }
 
 func (rs *CHRoleService) CreateRole(ctx context.Context, name string, database string, privileges []string) (*CHRole, error) {
-	conn := *rs.CHConnection
-	err := conn.Exec(ctx, fmt.Sprintf("CREATE ROLE %s", name))
+	conn := rs.CHConnection
+	err := conn.Exec(ctx, fmt.Sprintf("CREATE ROLE %s", common.QuoteIdentifier(name)))
 	if err != nil {
 		return nil, fmt.Errorf("error creating role: %s", err)
 	}
@@ -157,7 +165,7 @@ func (rs *CHRoleService) CreateRole(ctx context.Context, name string, database s
 		err = conn.Exec(ctx, getGrantQuery(name, []string{privilege}, database))
 		if err != nil {
 			// Rollback
-			err2 := conn.Exec(ctx, fmt.Sprintf("DROP ROLE %s", name))
+			err2 := conn.Exec(ctx, fmt.Sprintf("DROP ROLE %s", common.QuoteIdentifier(name)))
 			if err2 != nil {
 				return nil, fmt.Errorf("error creating role: %s:%s", err, err2)
 			}
@@ -169,5 +177,5 @@ func (rs *CHRoleService) CreateRole(ctx context.Context, name string, database s
 }
 
 func (rs *CHRoleService) DeleteRole(ctx context.Context, name string) error {
-	return (*rs.CHConnection).Exec(ctx, fmt.Sprintf("DROP ROLE %s", name))
+	return rs.CHConnection.Exec(ctx, fmt.Sprintf("DROP ROLE %s", common.QuoteIdentifier(name)))
 }
@@ -0,0 +1,133 @@
+package resourcerole
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestGetRole(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.roles", chfake.Row{"name": "reader"}, chfake.Row{"name": "admin"})
+	fake.AddRows("system.grants",
+		chfake.Row{"role_name": "reader", "access_type": "SELECT", "database": "db"},
+		chfake.Row{"role_name": "admin", "access_type": "ALL", "database": ""},
+	)
+	service := CHRoleService{CHConnection: fake}
+
+	role, err := service.GetRole(context.Background(), "admin")
+	if err != nil {
+		t.Fatalf("GetRole failed: %v", err)
+	}
+	expected := CHRole{Name: "admin", Privileges: []CHGrant{{RoleName: "admin", AccessType: "ALL", Database: "*"}}}
+	if !reflect.DeepEqual(*role, expected) {
+		t.Errorf("GetRole() = %+v, expected %+v", *role, expected)
+	}
+
+	missing, err := service.GetRole(context.Background(), "missing")
+	if err != nil || missing != nil {
+		t.Errorf("GetRole() of a missing role = %v, %v, expected nil", missing, err)
+	}
+}
+
+func TestCreateRole(t *testing.T) {
+	fake := chfake.New()
+	service := CHRoleService{CHConnection: fake}
+
+	role, err := service.CreateRole(context.Background(), "reader", "db", []string{"SELECT", "SHOW TABLES"})
+	if err != nil {
+		t.Fatalf("CreateRole failed: %v", err)
+	}
+	if len(role.Privileges) != 2 {
+		t.Errorf("unexpected role %+v", role)
+	}
+	expected := []string{
+		"CREATE ROLE `reader`",
+		"GRANT SELECT ON `db`.* TO `reader`",
+		"GRANT SHOW TABLES ON `db`.* TO `reader`",
+	}
+	if !reflect.DeepEqual(fake.Statements, expected) {
+		t.Errorf("unexpected statements %q, expected %q", fake.Statements, expected)
+	}
+}
+
+func TestCreateRoleRollback(t *testing.T) {
+	fake := chfake.New()
+	fake.FailOn("GRANT CURRENT GRANTS", errors.New("not enough privileges"))
+	service := CHRoleService{CHConnection: fake}
+
+	if _, err := service.CreateRole(context.Background(), "admin", "*", []string{"ALL"}); err == nil {
+		t.Fatalf("CreateRole expected to fail")
+	}
+	expected := []string{
+		"CREATE ROLE `admin`",
+		"GRANT CURRENT GRANTS (ALL ON *.*) TO `admin`",
+		"DROP ROLE `admin`",
+	}
+	if !reflect.DeepEqual(fake.Statements, expected) {
+		t.Errorf("unexpected statements %q, expected %q", fake.Statements, expected)
+	}
+}
+
+func TestGetGrantQuery(t *testing.T) {
+	statements := []string{
+		getGrantQuery("reader", []string{"SELECT", "SHOW TABLES"}, "db"),
+		getGrantQuery("admin", []string{"ALL"}, "*"),
+		getGrantQuery("monitor", []string{"SELECT"}, "system"),
+		getGrantQuery("it's", []string{"CREATE FUNCTION"}, "my`db"),
+	}
+	golden.Assert(t, "grant_queries", strings.Join(statements, "\n"))
+}
+
+func TestRoleWithGrants(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.roles", chfake.Row{"name": "reader"})
+	// The role grants SELECT on db, clickhouse_grant resources grant the others to the same role
+	fake.AddRows("system.grants",
+		chfake.Row{"role_name": "reader", "access_type": "SELECT", "database": "db"},
+		chfake.Row{"role_name": "reader", "access_type": "INSERT", "database": "db"},
+		chfake.Row{"role_name": "reader", "access_type": "ALTER", "database": "other"},
+		chfake.Row{"role_name": "reader", "access_type": "SELECT", "database": "other", "table": "events"},
+		chfake.Row{"role_name": "reader", "access_type": "SELECT", "database": "db", "table": "users", "column": "id"},
+	)
+	service := CHRoleService{CHConnection: fake}
+	ctx := context.Background()
+
+	chRole, err := service.GetRole(ctx, "reader")
+	if err != nil {
+		t.Fatalf("GetRole failed: %v", err)
+	}
+	role, err := chRole.ToRoleResource("db", []string{"select"})
+	if err != nil {
+		t.Fatalf("ToRoleResource failed: %v", err)
+	}
+	if role.Database != "db" || !reflect.DeepEqual(common.StringSetToList(role.Privileges), []string{"select"}) {
+		t.Errorf("ToRoleResource() = %s %v, expected db [select]", role.Database, common.StringSetToList(role.Privileges))
+	}
+
+	// Removing the privilege of the role leaves the ones of the grants
+	state := ResourceRole().Data(nil)
+	state.SetId("reader")
+	for key, value := range map[string]any{"name": "reader", "database": "db", "privileges": []any{"select"}} {
+		if err := state.Set(key, value); err != nil {
+			t.Fatalf("setting %s failed: %v", key, err)
+		}
+	}
+	d := ResourceRole().Data(state.State())
+	if err := d.Set("privileges", []any{}); err != nil {
+		t.Fatalf("setting privileges failed: %v", err)
+	}
+	if _, err := service.UpdateRole(ctx, RoleResource{Name: "reader", Database: "db", Privileges: schema.NewSet(schema.HashString, nil)}, d); err != nil {
+		t.Fatalf("UpdateRole failed: %v", err)
+	}
+	if expected := []string{"REVOKE select ON `db`.* FROM `reader`"}; !reflect.DeepEqual(fake.Statements, expected) {
+		t.Errorf("unexpected statements %q, expected %q", fake.Statements, expected)
+	}
+}
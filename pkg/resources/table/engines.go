@@ -0,0 +1,278 @@
+package resourcetable
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type engineSpec struct {
+	// Engine belongs to the MergeTree family, so it supports ORDER BY, PARTITION BY and the rest of MergeTree clauses
+	mergeTree bool
+	// Engine takes the ZooKeeper path and replica name as first (optional) params
+	replicated bool
+	minParams  int
+	maxParams  int
+	// Validates the engine own params, replication params excluded
+	validateParams func(t *TableResource, params []string) []string
+	// Adapts the engine own params read from engine_full to the way they are written in state
+	normalizeParams func(stateParams []string, readParams []string) []string
+}
+
+var mergeTreeEngines = map[string]engineSpec{
+	"MergeTree": {
+		mergeTree: true,
+	},
+	"ReplacingMergeTree": {
+		mergeTree:      true,
+		maxParams:      2,
+		validateParams: validateColumnParams("version", "is_deleted"),
+	},
+	"SummingMergeTree": {
+		mergeTree:       true,
+		maxParams:       1,
+		validateParams:  validateSummingParams,
+		normalizeParams: normalizeSummingParams,
+	},
+	"AggregatingMergeTree": {
+		mergeTree: true,
+	},
+	"CollapsingMergeTree": {
+		mergeTree:      true,
+		minParams:      1,
+		maxParams:      1,
+		validateParams: validateColumnParams("sign"),
+	},
+	"VersionedCollapsingMergeTree": {
+		mergeTree:      true,
+		minParams:      2,
+		maxParams:      2,
+		validateParams: validateColumnParams("sign", "version"),
+	},
+	"GraphiteMergeTree": {
+		mergeTree:      true,
+		minParams:      1,
+		maxParams:      1,
+		validateParams: validateStringParams("config_section"),
+	},
+}
+
+var otherEngines = map[string]engineSpec{
+	"Distributed": {
+		minParams:       3,
+		maxParams:       5,
+		validateParams:  validateDistributedParams,
+		normalizeParams: normalizeDistributedParams,
+	},
+	"Buffer": {
+		minParams: 9,
+		maxParams: 12,
+	},
+	"Merge": {
+		minParams: 2,
+		maxParams: 2,
+	},
+	"Memory":    {},
+	"Null":      {},
+	"Log":       {},
+	"TinyLog":   {},
+	"StripeLog": {},
+}
+
+// EngineSpecs holds every supported table engine, MergeTree family engines are also available as Replicated*.
+var EngineSpecs = buildEngineSpecs()
+
+func buildEngineSpecs() map[string]engineSpec {
+	specs := make(map[string]engineSpec)
+	for name, spec := range mergeTreeEngines {
+		specs[name] = spec
+		replicatedSpec := spec
+		replicatedSpec.replicated = true
+		specs["Replicated"+name] = replicatedSpec
+	}
+	for name, spec := range otherEngines {
+		specs[name] = spec
+	}
+	return specs
+}
+
+// SupportedEngines returns the supported engine names sorted alphabetically.
+func SupportedEngines() []string {
+	var engines []string
+	for name := range EngineSpecs {
+		engines = append(engines, name)
+	}
+	sort.Strings(engines)
+	return engines
+}
+
+func IsMergeTreeEngine(engine string) bool {
+	return EngineSpecs[engine].mergeTree
+}
+
+// viewEngines are the engines of the view like objects listed in system.tables, managed by their own resources.
+var viewEngines = map[string]bool{
+	"View":             true,
+	"MaterializedView": true,
+	"Dictionary":       true,
+}
+
+func IsViewEngine(engine string) bool {
+	return viewEngines[engine]
+}
+
+var engineIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func isStringLiteral(param string) bool {
+	return len(param) >= 2 && strings.HasPrefix(param, "'") && strings.HasSuffix(param, "'")
+}
+
+func validateColumnParams(names ...string) func(t *TableResource, params []string) []string {
+	return func(t *TableResource, params []string) []string {
+		var errors []string
+		for i, param := range params {
+			if !t.HasColumn(param) {
+				errors = append(errors, fmt.Sprintf("%s engine param '%s' is not a column", names[i], param))
+			}
+		}
+		return errors
+	}
+}
+
+func validateStringParams(names ...string) func(t *TableResource, params []string) []string {
+	return func(t *TableResource, params []string) []string {
+		var errors []string
+		for i, param := range params {
+			if !isStringLiteral(param) {
+				errors = append(errors, fmt.Sprintf("%s engine param %s must be a quoted string", names[i], param))
+			}
+		}
+		return errors
+	}
+}
+
+// validateSummingParams checks the columns to sum, either a single column or a tuple like (a, b).
+func validateSummingParams(t *TableResource, params []string) []string {
+	var errors []string
+	for _, param := range params {
+		for _, column := range splitTopLevel(unwrapParentheses(param)) {
+			if !t.HasColumn(column) {
+				errors = append(errors, fmt.Sprintf("columns engine param '%s' is not a column", column))
+			}
+		}
+	}
+	return errors
+}
+
+// normalizeSummingParams keeps the columns to sum as written in state when engine_full only differs
+// in the parentheses around them.
+func normalizeSummingParams(stateParams []string, readParams []string) []string {
+	if len(stateParams) == 1 && len(readParams) == 1 && unwrapParentheses(stateParams[0]) == unwrapParentheses(readParams[0]) {
+		return stateParams
+	}
+	return readParams
+}
+
+// validateDistributedParams checks the Distributed(cluster, database, table[, sharding_key[, policy_name]]) params.
+func validateDistributedParams(t *TableResource, params []string) []string {
+	var errors []string
+	for i, name := range []string{"cluster", "database", "table"} {
+		if !isStringLiteral(params[i]) && !engineIdentifierRegex.MatchString(params[i]) {
+			errors = append(errors, fmt.Sprintf("%s engine param %s must be an identifier or a quoted string", name, params[i]))
+		}
+	}
+	if len(params) > 3 && strings.TrimSpace(params[3]) == "" {
+		errors = append(errors, "sharding_key engine param can't be empty")
+	}
+	if len(params) > 4 && !isStringLiteral(params[4]) {
+		errors = append(errors, fmt.Sprintf("policy_name engine param %s must be a quoted string", params[4]))
+	}
+	return errors
+}
+
+// normalizeDistributedParams keeps the cluster, database and table as written in state, engine_full reports
+// them as string literals even when they were given as identifiers.
+func normalizeDistributedParams(stateParams []string, readParams []string) []string {
+	if len(stateParams) != len(readParams) {
+		return readParams
+	}
+	params := make([]string, len(readParams))
+	copy(params, readParams)
+	for i := 0; i < 3 && i < len(params); i++ {
+		if strings.Trim(stateParams[i], "'") == strings.Trim(readParams[i], "'") {
+			params[i] = stateParams[i]
+		}
+	}
+	return params
+}
+
+// engineOwnParams strips the replication params (ZooKeeper path and replica name) from the engine params.
+func (spec engineSpec) engineOwnParams(params []string) []string {
+	if spec.replicated && len(params) >= 2 && isStringLiteral(params[0]) && isStringLiteral(params[1]) {
+		return params[2:]
+	}
+	return params
+}
+
+func (t *TableResource) validateEngine() []string {
+	spec, ok := EngineSpecs[t.Engine]
+	if !ok {
+		return []string{fmt.Sprintf("engine %s is not supported", t.Engine)}
+	}
+
+	var errors []string
+	params := spec.engineOwnParams(t.EngineParams)
+	if spec.minParams == spec.maxParams && len(params) != spec.minParams {
+		errors = append(errors, fmt.Sprintf("%s engine expects exactly %d params, got %d", t.Engine, spec.minParams, len(params)))
+	} else if len(params) < spec.minParams || len(params) > spec.maxParams {
+		errors = append(errors, fmt.Sprintf("%s engine expects between %d and %d params, got %d", t.Engine, spec.minParams, spec.maxParams, len(params)))
+	} else if spec.validateParams != nil {
+		errors = append(errors, spec.validateParams(t, params)...)
+	}
+
+	if !spec.mergeTree && (len(t.OrderBy) > 0 || len(t.PartitionBy) > 0) {
+		errors = append(errors, fmt.Sprintf("%s engine doesn't support order_by or partition_by", t.Engine))
+	}
+	return errors
+}
+
+// defaultReplicationParams are the ZooKeeper paths and replica name Clickhouse fills a Replicated engine
+// created without them with, from its default_replica_path and default_replica_name settings: the server
+// default for Atomic databases and the {database}/{table} one of the documentation.
+var defaultReplicationParams = [][2]string{
+	{"'/clickhouse/tables/{uuid}/{shard}'", "'{replica}'"},
+	{"'/clickhouse/tables/{shard}/{database}/{table}'", "'{replica}'"},
+}
+
+func isDefaultReplicationParams(path string, replica string) bool {
+	for _, params := range defaultReplicationParams {
+		if path == params[0] && replica == params[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeEngineParams adapts the engine params read from engine_full to the ones known in state:
+// when the ZooKeeper path and replica name of a Replicated engine were left to the server defaults
+// they are reported by engine_full, but they must not be considered a change. Other replication params
+// are kept, e.g. on import. The engine own params are then normalized by the engine itself, e.g.
+// Distributed reports its database and table quoted.
+func NormalizeEngineParams(engine string, stateParams []string, readParams []string) []string {
+	spec := EngineSpecs[engine]
+	if spec.replicated && len(stateParams) == len(readParams)-2 && len(spec.engineOwnParams(readParams)) == len(stateParams) &&
+		isDefaultReplicationParams(readParams[0], readParams[1]) {
+		readParams = readParams[2:]
+	}
+	if spec.normalizeParams == nil {
+		return readParams
+	}
+
+	replicationParams := len(readParams) - len(spec.engineOwnParams(readParams))
+	if len(stateParams) < replicationParams {
+		return readParams
+	}
+	ownParams := spec.normalizeParams(stateParams[replicationParams:], readParams[replicationParams:])
+	return append(append(make([]string, 0), readParams[:replicationParams]...), ownParams...)
+}
@@ -0,0 +1,62 @@
+package resourcetable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateEngine(t *testing.T) {
+	columns := []interface{}{
+		map[string]interface{}{"name": "a", "type": "UInt64", "default_kind": "", "default_expression": "", "codec": "", "ttl": "", "comment": ""},
+		map[string]interface{}{"name": "b", "type": "UInt64", "default_kind": "", "default_expression": "", "codec": "", "ttl": "", "comment": ""},
+	}
+	tests := []struct {
+		engine   string
+		params   []string
+		expected []string
+	}{
+		{"CollapsingMergeTree", []string{}, []string{"CollapsingMergeTree engine expects exactly 1 params, got 0"}},
+		{"ReplicatedCollapsingMergeTree", []string{"'/path'", "'{replica}'", "a"}, nil},
+		{"ReplacingMergeTree", []string{"a", "b", "c"}, []string{"ReplacingMergeTree engine expects between 0 and 2 params, got 3"}},
+		{"SummingMergeTree", []string{}, nil},
+		{"SummingMergeTree", []string{"(a, b)"}, nil},
+		{"SummingMergeTree", []string{"(a, c)"}, []string{"columns engine param 'c' is not a column"}},
+		{"Distributed", []string{"'{cluster}'", "db", "'table'", "rand()", "'policy'"}, nil},
+		{"Distributed", []string{"'{cluster}'", "db.x", "table"}, []string{"database engine param db.x must be an identifier or a quoted string"}},
+		{"Distributed", []string{"cluster", "db", "table", "rand()", "policy"}, []string{"policy_name engine param policy must be a quoted string"}},
+	}
+
+	for _, test := range tests {
+		table := TableResource{Engine: test.engine, EngineParams: test.params, Columns: columns}
+		if errors := table.validateEngine(); !reflect.DeepEqual(errors, test.expected) {
+			t.Errorf("validateEngine(%s%v) = %v, expected %v", test.engine, test.params, errors, test.expected)
+		}
+	}
+}
+
+func TestNormalizeEngineParams(t *testing.T) {
+	tests := []struct {
+		engine   string
+		state    []string
+		read     []string
+		expected []string
+	}{
+		{"ReplicatedMergeTree", []string{}, []string{"'/clickhouse/tables/{uuid}/{shard}'", "'{replica}'"}, []string{}},
+		{"ReplicatedSummingMergeTree", []string{"a"}, []string{"'/clickhouse/tables/{shard}/{database}/{table}'", "'{replica}'", "a"}, []string{"a"}},
+		{"ReplicatedSummingMergeTree", []string{"a"}, []string{"'/path'", "'{replica}'", "a"}, []string{"'/path'", "'{replica}'", "a"}},
+		// Import, nothing is known in state
+		{"ReplicatedMergeTree", []string{}, []string{"'/clickhouse/tables/{shard}/events'", "'{replica}'"}, []string{"'/clickhouse/tables/{shard}/events'", "'{replica}'"}},
+		{"ReplicatedMergeTree", []string{}, []string{"'/clickhouse/tables/{uuid}/{shard}'", "'replica_1'"}, []string{"'/clickhouse/tables/{uuid}/{shard}'", "'replica_1'"}},
+		{"SummingMergeTree", []string{"a"}, []string{"(a)"}, []string{"a"}},
+		{"SummingMergeTree", []string{"a"}, []string{"(a, b)"}, []string{"(a, b)"}},
+		{"ReplicatedSummingMergeTree", []string{"'/path'", "'{replica}'", "(a)"}, []string{"'/path'", "'{replica}'", "a"}, []string{"'/path'", "'{replica}'", "(a)"}},
+		{"Distributed", []string{"'{cluster}'", "db", "events"}, []string{"'{cluster}'", "'db'", "'events'"}, []string{"'{cluster}'", "db", "events"}},
+		{"Distributed", []string{"'{cluster}'", "db", "events"}, []string{"'{cluster}'", "'db'", "'other'"}, []string{"'{cluster}'", "db", "'other'"}},
+	}
+
+	for _, test := range tests {
+		if normalized := NormalizeEngineParams(test.engine, test.state, test.read); !reflect.DeepEqual(normalized, test.expected) {
+			t.Errorf("NormalizeEngineParams(%s, %v, %v) = %v, expected %v", test.engine, test.state, test.read, normalized, test.expected)
+		}
+	}
+}
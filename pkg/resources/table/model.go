@@ -4,23 +4,40 @@ import (
 	"fmt"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"regexp"
+	"strings"
 )
 
 type CHTable struct {
-	Database   string     `ch:"database"`
-	Name       string     `ch:"name"`
-	EngineFull string     `ch:"engine_full"`
-	Engine     string     `ch:"engine"`
-	Comment    string     `ch:"comment"`
-	Columns    []CHColumn `ch:"columns"`
+	Database         string     `ch:"database"`
+	Name             string     `ch:"name"`
+	EngineFull       string     `ch:"engine_full"`
+	Engine           string     `ch:"engine"`
+	Comment          string     `ch:"comment"`
+	SortingKey       string     `ch:"sorting_key"`
+	PartitionKey     string     `ch:"partition_key"`
+	PrimaryKey       string     `ch:"primary_key"`
+	SamplingKey      string     `ch:"sampling_key"`
+	CreateTableQuery string     `ch:"create_table_query"`
+	Columns          []CHColumn `ch:"columns"`
+	Indexes          []CHIndex  `ch:"indexes"`
+}
+
+type CHIndex struct {
+	Name        string `ch:"name"`
+	Expression  string `ch:"expr"`
+	Type        string `ch:"type_full"`
+	Granularity uint64 `ch:"granularity"`
 }
 
 type CHColumn struct {
-	Database string `ch:"database"`
-	Table    string `ch:"table"`
-	Name     string `ch:"name"`
-	Type     string `ch:"type"`
+	Database          string `ch:"database"`
+	Table             string `ch:"table"`
+	Name              string `ch:"name"`
+	Type              string `ch:"type"`
+	DefaultKind       string `ch:"default_kind"`
+	DefaultExpression string `ch:"default_expression"`
+	CompressionCodec  string `ch:"compression_codec"`
+	Comment           string `ch:"comment"`
 }
 
 type TableResource struct {
@@ -34,11 +51,22 @@ type TableResource struct {
 	OrderBy      []string
 	Columns      []interface{}
 	PartitionBy  []PartitionByResource
+	PrimaryKey   []string
+	SampleBy     string
+	TTL          []TTLResource
+	Settings     map[string]string
+	Indexes      []IndexResource
+	Projections  []ProjectionResource
 }
 
 type ColumnResource struct {
-	Name string
-	Type string
+	Name              string
+	Type              string
+	DefaultKind       string
+	DefaultExpression string
+	Codec             string
+	TTL               string
+	Comment           string
 }
 
 type PartitionByResource struct {
@@ -46,12 +74,41 @@ type PartitionByResource struct {
 	PartitionFunction string
 }
 
+type IndexResource struct {
+	Name        string
+	Expression  string
+	Type        string
+	Granularity int
+	Materialize bool
+}
+
+type ProjectionResource struct {
+	Name        string
+	Query       string
+	Materialize bool
+}
+
+type TTLResource struct {
+	Expression string
+	Action     string
+	Target     string
+	Where      string
+	GroupBy    string
+	Set        string
+}
+
 func (t *CHTable) ColumnsToResource() []interface{} {
 	var columnResources []interface{}
+	ttls := parseColumnTTLs(t.CreateTableQuery)
 	for _, column := range t.Columns {
 		columnResource := map[string]interface{}{
-			"name": column.Name,
-			"type": column.Type,
+			"name":               column.Name,
+			"type":               column.Type,
+			"default_kind":       column.DefaultKind,
+			"default_expression": column.DefaultExpression,
+			"codec":              unwrapCodec(column.CompressionCodec),
+			"comment":            column.Comment,
+			"ttl":                ttls[column.Name],
 		}
 		columnResources = append(columnResources, columnResource)
 	}
@@ -59,36 +116,38 @@ func (t *CHTable) ColumnsToResource() []interface{} {
 	return columnResources
 }
 
-func (t *CHTable) ToResource() (*TableResource, error) {
-	tableResource := TableResource{
-		Database:   t.Database,
-		Name:       t.Name,
-		EngineFull: t.EngineFull,
-		Engine:     t.Engine,
-		Columns:    t.ColumnsToResource(),
-	}
-
-	r, _ := regexp.Compile("MergeTree\\((?P<engine_params>[^)]*)\\)")
-	matches := r.FindStringSubmatch(t.EngineFull)
-	engineParamsIndex := r.SubexpIndex("engine_params")
-	engineParams := make([]string, 0)
-	if engineParamsIndex != -1 {
-
-		regex := regexp.MustCompile("[, ]+")
-		params := regex.Split(matches[r.SubexpIndex("engine_params")], -1)
-		for _, p := range params {
-			engineParams = append(engineParams, p)
-		}
+func (t *CHTable) IndexesToResource() []IndexResource {
+	indexResources := make([]IndexResource, 0)
+	for _, index := range t.Indexes {
+		indexResources = append(indexResources, IndexResource{
+			Name:        index.Name,
+			Expression:  index.Expression,
+			Type:        index.Type,
+			Granularity: int(index.Granularity),
+		})
 	}
+	return indexResources
+}
 
-	comment, cluster, err := common.UnmarshalComment(t.Comment)
-	if err != nil {
-		return nil, err
+func (t *CHTable) ToResource() (*TableResource, error) {
+	tableResource := TableResource{
+		Database:     t.Database,
+		Name:         t.Name,
+		EngineFull:   t.EngineFull,
+		Engine:       t.Engine,
+		Columns:      t.ColumnsToResource(),
+		EngineParams: parseEngineParams(t.Engine, t.EngineFull),
+		OrderBy:      parseSortingKey(t.SortingKey),
+		PartitionBy:  parsePartitionKey(t.PartitionKey),
+		PrimaryKey:   parseSortingKey(t.PrimaryKey),
+		SampleBy:     t.SamplingKey,
+		TTL:          parseTTLClause(extractClause(createTableStorage(t.CreateTableQuery), "TTL", "SETTINGS", "COMMENT")),
+		Settings:     parseSettingsClause(extractClause(createTableStorage(t.CreateTableQuery), "SETTINGS", "COMMENT")),
+		Indexes:      t.IndexesToResource(),
+		Projections:  parseProjections(t.CreateTableQuery),
 	}
 
-	tableResource.Cluster = cluster
-	tableResource.Comment = comment
-	tableResource.EngineParams = engineParams
+	tableResource.Comment, tableResource.Cluster = common.DecodeComment(t.Comment)
 
 	return &tableResource, nil
 }
@@ -96,9 +155,15 @@ func (t *CHTable) ToResource() (*TableResource, error) {
 func (t *TableResource) GetColumnsResourceList() []ColumnResource {
 	var columnResources []ColumnResource
 	for _, column := range t.Columns {
+		columnMap := column.(map[string]interface{})
 		columnResources = append(columnResources, ColumnResource{
-			Name: column.(map[string]interface{})["name"].(string),
-			Type: column.(map[string]interface{})["type"].(string),
+			Name:              columnMap["name"].(string),
+			Type:              columnMap["type"].(string),
+			DefaultKind:       columnMap["default_kind"].(string),
+			DefaultExpression: columnMap["default_expression"].(string),
+			Codec:             columnMap["codec"].(string),
+			TTL:               columnMap["ttl"].(string),
+			Comment:           columnMap["comment"].(string),
 		})
 	}
 	return columnResources
@@ -114,6 +179,183 @@ func (t *TableResource) SetPartitionBy(partitionBy []interface{}) {
 	}
 }
 
+func (t *TableResource) PartitionByToResource() []interface{} {
+	partitionByResources := make([]interface{}, 0)
+	for _, partitionBy := range t.PartitionBy {
+		partitionByResources = append(partitionByResources, map[string]interface{}{
+			"by":                 partitionBy.By,
+			"partition_function": partitionBy.PartitionFunction,
+		})
+	}
+	return partitionByResources
+}
+
+func (t *TableResource) SetTTL(ttls []interface{}) {
+	t.TTL = make([]TTLResource, 0)
+	for _, ttl := range ttls {
+		ttlMap := ttl.(map[string]interface{})
+		t.TTL = append(t.TTL, TTLResource{
+			Expression: ttlMap["expression"].(string),
+			Action:     ttlMap["action"].(string),
+			Target:     ttlMap["target"].(string),
+			Where:      ttlMap["where"].(string),
+			GroupBy:    ttlMap["group_by"].(string),
+			Set:        ttlMap["set"].(string),
+		})
+	}
+}
+
+func (t *TableResource) TTLToResource() []interface{} {
+	ttlResources := make([]interface{}, 0)
+	for _, ttl := range t.TTL {
+		ttlResources = append(ttlResources, map[string]interface{}{
+			"expression": ttl.Expression,
+			"action":     ttl.Action,
+			"target":     ttl.Target,
+			"where":      ttl.Where,
+			"group_by":   ttl.GroupBy,
+			"set":        ttl.Set,
+		})
+	}
+	return ttlResources
+}
+
+func (t *TableResource) SetSettings(settings map[string]interface{}) {
+	t.Settings = make(map[string]string)
+	for key, value := range settings {
+		t.Settings[key] = value.(string)
+	}
+}
+
+// KeepStateKeys reconciles the keys, TTL and settings read from Clickhouse with the state ones:
+// server defaults (primary key equal to the sorting key, settings not set by the user) and equivalent
+// key or TTL expressions are not considered drift.
+func (t *TableResource) KeepStateKeys(state *TableResource) {
+	if normalizeExpression(strings.Join(state.OrderBy, ",")) == normalizeExpression(strings.Join(t.OrderBy, ",")) {
+		t.OrderBy = state.OrderBy
+	}
+	if normalizeExpression(buildPartitionByClause(state.PartitionBy)) == normalizeExpression(buildPartitionByClause(t.PartitionBy)) {
+		t.PartitionBy = state.PartitionBy
+	}
+	primaryKey := normalizeExpression(strings.Join(t.PrimaryKey, ","))
+	if len(state.PrimaryKey) == 0 && primaryKey == normalizeExpression(strings.Join(t.OrderBy, ",")) {
+		t.PrimaryKey = make([]string, 0)
+	} else if primaryKey == normalizeExpression(strings.Join(state.PrimaryKey, ",")) {
+		t.PrimaryKey = state.PrimaryKey
+	}
+
+	if normalizeExpression(buildTTLClause(state.TTL)) == normalizeExpression(buildTTLClause(t.TTL)) {
+		t.TTL = state.TTL
+	}
+
+	settings := make(map[string]string)
+	for key, value := range t.Settings {
+		if _, ok := state.Settings[key]; ok {
+			settings[key] = value
+		}
+	}
+	t.Settings = settings
+}
+
+// PartitionByExpressions returns the partition key as a list of expressions, e.g. toYYYYMM(event_date).
+func (t *TableResource) PartitionByExpressions() []string {
+	expressions := make([]string, 0)
+	for _, partitionBy := range t.PartitionBy {
+		expressions = append(expressions, buildPartitionByItem(partitionBy))
+	}
+	return expressions
+}
+
+func (t *TableResource) SetIndexes(indexes []interface{}) {
+	t.Indexes = make([]IndexResource, 0)
+	for _, index := range indexes {
+		indexMap := index.(map[string]interface{})
+		t.Indexes = append(t.Indexes, IndexResource{
+			Name:        indexMap["name"].(string),
+			Expression:  indexMap["expression"].(string),
+			Type:        indexMap["type"].(string),
+			Granularity: indexMap["granularity"].(int),
+			Materialize: indexMap["materialize"].(bool),
+		})
+	}
+}
+
+func (t *TableResource) IndexesToResource() []interface{} {
+	indexResources := make([]interface{}, 0)
+	for _, index := range t.Indexes {
+		indexResources = append(indexResources, map[string]interface{}{
+			"name":        index.Name,
+			"expression":  index.Expression,
+			"type":        index.Type,
+			"granularity": index.Granularity,
+			"materialize": index.Materialize,
+		})
+	}
+	return indexResources
+}
+
+func (t *TableResource) SetProjections(projections []interface{}) {
+	t.Projections = make([]ProjectionResource, 0)
+	for _, projection := range projections {
+		projectionMap := projection.(map[string]interface{})
+		t.Projections = append(t.Projections, ProjectionResource{
+			Name:        projectionMap["name"].(string),
+			Query:       projectionMap["query"].(string),
+			Materialize: projectionMap["materialize"].(bool),
+		})
+	}
+}
+
+func (t *TableResource) ProjectionsToResource() []interface{} {
+	projectionResources := make([]interface{}, 0)
+	for _, projection := range t.Projections {
+		projectionResources = append(projectionResources, map[string]interface{}{
+			"name":        projection.Name,
+			"query":       projection.Query,
+			"materialize": projection.Materialize,
+		})
+	}
+	return projectionResources
+}
+
+// KeepStateIndexes keeps the state version of the indexes and projections equivalent to the ones read from
+// Clickhouse, so the user formatting and the materialize flag, which can't be read back, are preserved.
+func (t *TableResource) KeepStateIndexes(state *TableResource) {
+	stateIndexes := make(map[string]IndexResource)
+	for _, index := range state.Indexes {
+		stateIndexes[index.Name] = index
+	}
+	for i, index := range t.Indexes {
+		if stateIndex, ok := stateIndexes[index.Name]; ok && indexesEquivalent(stateIndex, index) {
+			t.Indexes[i] = stateIndex
+		}
+	}
+
+	stateProjections := make(map[string]ProjectionResource)
+	for _, projection := range state.Projections {
+		stateProjections[projection.Name] = projection
+	}
+	for i, projection := range t.Projections {
+		if stateProjection, ok := stateProjections[projection.Name]; ok && projectionsEquivalent(stateProjection, projection) {
+			t.Projections[i] = stateProjection
+		}
+	}
+}
+
+// referencesColumns checks the identifiers referenced by a key expression are columns,
+// nested fields like "point.x" are checked against their column.
+func (t *TableResource) referencesColumns(expression string, field string, diags *diag.Diagnostics) {
+	for _, identifier := range referencedIdentifiers(expression) {
+		if !t.HasColumn(identifier) && !t.HasColumn(strings.SplitN(identifier, ".", 2)[0]) {
+			*diags = append(*diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "wrong value",
+				Detail:   fmt.Sprintf("%s expression '%s' references '%s' which is not a column", field, expression, identifier),
+			})
+		}
+	}
+}
+
 func (t *TableResource) HasColumn(columnName string) bool {
 	for _, column := range t.GetColumnsResourceList() {
 		if column.Name == columnName {
@@ -123,31 +365,136 @@ func (t *TableResource) HasColumn(columnName string) bool {
 	return false
 }
 
-func (t *TableResource) Validate(diags diag.Diagnostics) {
-	t.validateOrderBy(diags)
-	t.validatePartitionBy(diags)
+func (t *TableResource) Validate() diag.Diagnostics {
+	var diags diag.Diagnostics
+	t.validateColumns(&diags)
+	t.validateOrderBy(&diags)
+	t.validatePartitionBy(&diags)
+	t.validateKeys(&diags)
+	t.validateIndexes(&diags)
+	for _, engineError := range t.validateEngine() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   engineError,
+		})
+	}
+	return diags
 }
 
-func (t *TableResource) validateOrderBy(diags diag.Diagnostics) {
-	for _, orderField := range t.OrderBy {
-		if t.HasColumn(orderField) == false {
-			diags = append(diags, diag.Diagnostic{
+func (t *TableResource) validateColumns(diags *diag.Diagnostics) {
+	for _, column := range t.GetColumnsResourceList() {
+		if column.DefaultKind == "" && column.DefaultExpression != "" {
+			*diags = append(*diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "wrong value",
+				Detail:   fmt.Sprintf("column '%s' default_expression requires a default_kind", column.Name),
+			})
+		}
+		if column.DefaultKind != "" && column.DefaultKind != "EPHEMERAL" && column.DefaultExpression == "" {
+			*diags = append(*diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "wrong value",
-				Detail:   fmt.Sprintf("order by field '%s' is not a column", orderField),
+				Detail:   fmt.Sprintf("column '%s' default_kind %s requires a default_expression", column.Name, column.DefaultKind),
 			})
 		}
 	}
 }
 
-func (t *TableResource) validatePartitionBy(diags diag.Diagnostics) {
-	for _, partitionBy := range t.PartitionBy {
-		if t.HasColumn(partitionBy.By) == false {
-			diags = append(diags, diag.Diagnostic{
+// keyContains tells whether the expression is one of the primary key ones, the sorting key when no primary key is set.
+func (t *TableResource) keyContains(expression string) bool {
+	key := t.PrimaryKey
+	if len(key) == 0 {
+		key = t.OrderBy
+	}
+	for _, keyExpression := range key {
+		if normalizeExpression(keyExpression) == normalizeExpression(expression) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TableResource) validateKeys(diags *diag.Diagnostics) {
+	if len(t.PrimaryKey) > len(t.OrderBy) || strings.Join(t.PrimaryKey, ",") != strings.Join(t.OrderBy[:len(t.PrimaryKey)], ",") {
+		*diags = append(*diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   "primary key must be a prefix of order by",
+		})
+	}
+	if t.SampleBy != "" && IsMergeTreeEngine(t.Engine) && !t.keyContains(t.SampleBy) {
+		*diags = append(*diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   fmt.Sprintf("sample_by '%s' must be part of the primary key", t.SampleBy),
+		})
+	}
+	if !IsMergeTreeEngine(t.Engine) && (len(t.PrimaryKey) > 0 || t.SampleBy != "" || len(t.TTL) > 0) {
+		*diags = append(*diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   fmt.Sprintf("%s engine doesn't support primary_key, sample_by or ttl", t.Engine),
+		})
+	}
+	for _, ttl := range t.TTL {
+		if (ttl.Action == "TO DISK" || ttl.Action == "TO VOLUME") && ttl.Target == "" {
+			*diags = append(*diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "wrong value",
+				Detail:   fmt.Sprintf("ttl '%s' action %s requires a target", ttl.Expression, ttl.Action),
+			})
+		}
+		if ttl.Action == "GROUP BY" && ttl.GroupBy == "" {
+			*diags = append(*diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "wrong value",
-				Detail:   fmt.Sprintf("partition by field '%s' is not a column", partitionBy.By),
+				Detail:   fmt.Sprintf("ttl '%s' action GROUP BY requires group_by", ttl.Expression),
 			})
 		}
 	}
 }
+
+func (t *TableResource) validateIndexes(diags *diag.Diagnostics) {
+	if !IsMergeTreeEngine(t.Engine) && (len(t.Indexes) > 0 || len(t.Projections) > 0) {
+		*diags = append(*diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   fmt.Sprintf("%s engine doesn't support index or projection", t.Engine),
+		})
+	}
+	names := make(map[string]bool)
+	for _, index := range t.Indexes {
+		if names[index.Name] {
+			*diags = append(*diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "wrong value",
+				Detail:   fmt.Sprintf("index '%s' is defined more than once", index.Name),
+			})
+		}
+		names[index.Name] = true
+	}
+	names = make(map[string]bool)
+	for _, projection := range t.Projections {
+		if names[projection.Name] {
+			*diags = append(*diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "wrong value",
+				Detail:   fmt.Sprintf("projection '%s' is defined more than once", projection.Name),
+			})
+		}
+		names[projection.Name] = true
+	}
+}
+
+func (t *TableResource) validateOrderBy(diags *diag.Diagnostics) {
+	for _, orderField := range t.OrderBy {
+		t.referencesColumns(orderField, "order by", diags)
+	}
+}
+
+func (t *TableResource) validatePartitionBy(diags *diag.Diagnostics) {
+	for _, partitionBy := range t.PartitionBy {
+		t.referencesColumns(partitionBy.By, "partition by", diags)
+	}
+}
@@ -3,8 +3,10 @@ package resourcetable
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -15,7 +17,16 @@ func ResourceTable() *schema.Resource {
 
 		CreateContext: resourceTableCreate,
 		ReadContext:   resourceTableRead,
+		UpdateContext: resourceTableUpdate,
 		DeleteContext: resourceTableDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTableImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"database": {
 				Description: "DB Name where the table will bellow",
@@ -27,7 +38,6 @@ func ResourceTable() *schema.Resource {
 				Description: "Database comment, it will be codified in a json along with come metadata information (like cluster name in case of clustering)",
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"name": {
 				Description: "Table Name",
@@ -42,24 +52,25 @@ func ResourceTable() *schema.Resource {
 				ForceNew:    true,
 			},
 			"engine": {
-				Description:      "Table engine type (Supported types so far: Distributed, ReplicatedReplacingMergeTree, ReplacingMergeTree)",
+				Description:      "Table engine type. Supported engines: the MergeTree family (MergeTree, ReplacingMergeTree, SummingMergeTree, AggregatingMergeTree, CollapsingMergeTree, VersionedCollapsingMergeTree, GraphiteMergeTree) and their Replicated* versions, Distributed, Buffer, Merge, Memory, Null, Log, TinyLog and StripeLog",
 				Type:             schema.TypeString,
 				Required:         true,
 				ForceNew:         true,
 				ValidateDiagFunc: ValidateOnClusterEngine,
 			},
 			"engine_params": {
-				Description: "Engine params in case the engine type requires them",
-				Type:        schema.TypeList,
-				Required:    true,
-				ForceNew:    true,
+				Description:      "Engine params in case the engine type requires them. Replicated engines take the quoted ZooKeeper path and replica name first, they can be omitted to use the server defaults",
+				Type:             schema.TypeList,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEngineParamQuotesDiff,
 				Elem: &schema.Schema{
 					Type:     schema.TypeString,
 					ForceNew: true,
 				},
 			},
 			"order_by": {
-				Description: "Order by columns to use as sorting key",
+				Description: "Sorting key, a list of columns or expressions like `cityHash64(user_id)`. Expressions are compared with the sorting key normalized by Clickhouse",
 				Type:        schema.TypeList,
 				Optional:    true,
 				ForceNew:    true,
@@ -69,20 +80,20 @@ func ResourceTable() *schema.Resource {
 				},
 			},
 			"partition_by": {
-				Description: "Partition Key to split data",
+				Description: "Partition Key to split data, each item is a column or an expression like `toMonday(ts)`. Expressions are compared with the partition key normalized by Clickhouse",
 				Type:        schema.TypeList,
 				Optional:    true,
 				ForceNew:    true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"by": {
-							Description: "Column to use as part of the partition key",
+							Description: "Column or expression to use as part of the partition key",
 							Type:        schema.TypeString,
 							Required:    true,
 							ForceNew:    true,
 						},
 						"partition_function": {
-							Description:      "Partition function, could be empty or one of following: toYYYYMM, toYYYYMMDD or toYYYYMMDDhhmmss",
+							Description:      "Function applied to `by`, e.g. toYYYYMM or toMonday. It can be left empty to write the whole expression in `by`",
 							Type:             schema.TypeString,
 							Optional:         true,
 							ValidateDiagFunc: ValidatePartitionBy,
@@ -92,25 +103,183 @@ func ResourceTable() *schema.Resource {
 					},
 				},
 			},
-			"column": {
-				Description: "Column",
+			"primary_key": {
+				Description: "Primary key columns, a prefix of order_by. The sorting key is used as primary key when not provided",
 				Type:        schema.TypeList,
 				Optional:    true,
 				ForceNew:    true,
+				Elem: &schema.Schema{
+					Type:     schema.TypeString,
+					ForceNew: true,
+				},
+			},
+			"sample_by": {
+				Description:      "Sampling expression, it must be part of the primary key",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressExpressionDiff,
+			},
+			"ttl": {
+				Description: "Table TTL rules, applied in place with ALTER TABLE MODIFY TTL",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"expression": {
+							Description: "TTL expression, e.g. `eventTime + INTERVAL 1 MONTH`",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"action": {
+							Description:      "Action once the TTL expires: DELETE, TO DISK, TO VOLUME or GROUP BY",
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "DELETE",
+							ValidateDiagFunc: ValidateTTLAction,
+						},
+						"target": {
+							Description: "Disk or volume name for TO DISK and TO VOLUME actions",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"where": {
+							Description: "Condition to filter the expired rows the action applies to",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"group_by": {
+							Description: "Grouping key for the GROUP BY action, a prefix of the primary key",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"set": {
+							Description: "Aggregations for the GROUP BY action, e.g. `value = sum(value)`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"settings": {
+				Description:      "Table settings like `index_granularity` or `storage_policy`, applied in place with ALTER TABLE MODIFY SETTING. Only the configured settings are tracked",
+				Type:             schema.TypeMap,
+				Optional:         true,
+				DiffSuppressFunc: suppressEngineParamQuotesDiff,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"index": {
+				Description: "Data skipping index, indexes are added and dropped in place with ALTER TABLE",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Index Name",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"expression": {
+							Description:      "Indexed expression, e.g. a column name or `lower(name)`",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressExpressionDiff,
+						},
+						"type": {
+							Description:      "Index type, e.g. `minmax`, `set(100)` or `bloom_filter(0.01)`",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressExpressionDiff,
+						},
+						"granularity": {
+							Description: "Number of granules covered by each index block",
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     1,
+						},
+						"materialize": {
+							Description: "Build the index for the existing data when it's added to an existing table",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+					},
+				},
+			},
+			"projection": {
+				Description: "Projection, projections are added and dropped in place with ALTER TABLE",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Projection Name",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"query": {
+							Description:      "Projection query, e.g. `SELECT * ORDER BY userId`",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressExpressionDiff,
+						},
+						"materialize": {
+							Description: "Build the projection for the existing data when it's added to an existing table",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+					},
+				},
+			},
+			"column": {
+				Description: "Column. Columns are added, dropped, renamed, retyped and reordered in place with ALTER TABLE. A column is renamed when only its name changes, keeping its position",
+				Type:        schema.TypeList,
+				Optional:    true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
 							Description: "Column Name",
 							Type:        schema.TypeString,
 							Required:    true,
-							ForceNew:    true,
 						},
 						"type": {
-							Description:      "Column Type",
+							Description:      "Column Type, any Clickhouse type expression like `Nullable(String)` or `Map(String, Array(UInt64))`",
 							Type:             schema.TypeString,
 							Required:         true,
 							ValidateDiagFunc: ValidateType,
-							ForceNew:         true,
+							DiffSuppressFunc: suppressTypeDiff,
+						},
+						"default_kind": {
+							Description:      "Kind of default value: DEFAULT, MATERIALIZED, ALIAS or EPHEMERAL",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateDiagFunc: ValidateDefaultKind,
+						},
+						"default_expression": {
+							Description:      "Expression computing the column value, required unless default_kind is EPHEMERAL",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressDefaultExpressionDiff,
+						},
+						"codec": {
+							Description:      "Compression codecs, e.g. `Delta, ZSTD`",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressCodecDiff,
+						},
+						"ttl": {
+							Description:      "Column TTL expression",
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressExpressionDiff,
+						},
+						"comment": {
+							Description: "Column comment",
+							Type:        schema.TypeString,
+							Optional:    true,
 						},
 					},
 				},
@@ -134,6 +303,11 @@ func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) di
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("reading Clickhouse table: %v", err))
 	}
+	if chTable == nil {
+		tflog.Warn(ctx, "Table not found, removing it from state", map[string]interface{}{"database": database, "table": tableName})
+		d.SetId("")
+		return diags
+	}
 
 	tableResource, err := chTable.ToResource()
 	if err != nil {
@@ -149,12 +323,59 @@ func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) di
 	if err := d.Set("engine", tableResource.Engine); err != nil {
 		return diag.FromErr(fmt.Errorf("setting engine: %v", err))
 	}
+	stateEngineParams := common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{}))
+	tableResource.EngineParams = NormalizeEngineParams(tableResource.Engine, stateEngineParams, tableResource.EngineParams)
 	if err := d.Set("engine_params", tableResource.EngineParams); err != nil {
 		return diag.FromErr(fmt.Errorf("setting engine_params: %v", err))
 	}
+	tableResource.Cluster = common.KnownCluster(tableResource.Cluster, d.Get("cluster").(string))
 	if err := d.Set("cluster", tableResource.Cluster); err != nil {
 		return diag.FromErr(fmt.Errorf("setting cluster: %v", err))
 	}
+	if common.IsLegacyComment(chTable.Comment) {
+		// Keeping the legacy comment as stored shows it as changed, so the next apply rewrites it with MODIFY COMMENT
+		tableResource.Comment = chTable.Comment
+	}
+	if err := d.Set("comment", tableResource.Comment); err != nil {
+		return diag.FromErr(fmt.Errorf("setting comment: %v", err))
+	}
+
+	stateTableResource := TableResource{
+		OrderBy:    common.MapArrayInterfaceToArrayOfStrings(d.Get("order_by").([]interface{})),
+		PrimaryKey: common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{})),
+	}
+	stateTableResource.SetPartitionBy(d.Get("partition_by").([]interface{}))
+	stateTableResource.SetTTL(d.Get("ttl").([]interface{}))
+	stateTableResource.SetSettings(d.Get("settings").(map[string]interface{}))
+	tableResource.KeepStateKeys(&stateTableResource)
+	stateTableResource.SetIndexes(d.Get("index").([]interface{}))
+	stateTableResource.SetProjections(d.Get("projection").([]interface{}))
+	tableResource.KeepStateIndexes(&stateTableResource)
+
+	if err := d.Set("order_by", tableResource.OrderBy); err != nil {
+		return diag.FromErr(fmt.Errorf("setting order_by: %v", err))
+	}
+	if err := d.Set("partition_by", tableResource.PartitionByToResource()); err != nil {
+		return diag.FromErr(fmt.Errorf("setting partition_by: %v", err))
+	}
+	if err := d.Set("primary_key", tableResource.PrimaryKey); err != nil {
+		return diag.FromErr(fmt.Errorf("setting primary_key: %v", err))
+	}
+	if err := d.Set("sample_by", tableResource.SampleBy); err != nil {
+		return diag.FromErr(fmt.Errorf("setting sample_by: %v", err))
+	}
+	if err := d.Set("ttl", tableResource.TTLToResource()); err != nil {
+		return diag.FromErr(fmt.Errorf("setting ttl: %v", err))
+	}
+	if err := d.Set("settings", tableResource.Settings); err != nil {
+		return diag.FromErr(fmt.Errorf("setting settings: %v", err))
+	}
+	if err := d.Set("index", tableResource.IndexesToResource()); err != nil {
+		return diag.FromErr(fmt.Errorf("setting index: %v", err))
+	}
+	if err := d.Set("projection", tableResource.ProjectionsToResource()); err != nil {
+		return diag.FromErr(fmt.Errorf("setting projection: %v", err))
+	}
 	if err := d.Set("column", tableResource.Columns); err != nil {
 		return diag.FromErr(fmt.Errorf("setting column: %v", err))
 	}
@@ -164,6 +385,26 @@ func resourceTableRead(ctx context.Context, d *schema.ResourceData, meta any) di
 	return diags
 }
 
+func resourceTableImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Same ID format written by resourceTableCreate, the cluster part may be empty: <cluster>:<database>:<table>
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) < 3 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return nil, fmt.Errorf("unexpected import id %q, expected <cluster>:<database>:<table>", d.Id())
+	}
+
+	if err := d.Set("cluster", strings.Join(parts[:len(parts)-2], ":")); err != nil {
+		return nil, fmt.Errorf("setting cluster: %v", err)
+	}
+	if err := d.Set("database", parts[len(parts)-2]); err != nil {
+		return nil, fmt.Errorf("setting database: %v", err)
+	}
+	if err := d.Set("name", parts[len(parts)-1]); err != nil {
+		return nil, fmt.Errorf("setting name: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceTableCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 
@@ -181,12 +422,18 @@ func resourceTableCreate(ctx context.Context, d *schema.ResourceData, meta any)
 	tableResource.EngineParams = common.MapArrayInterfaceToArrayOfStrings(d.Get("engine_params").([]interface{}))
 	tableResource.OrderBy = common.MapArrayInterfaceToArrayOfStrings(d.Get("order_by").([]interface{}))
 	tableResource.SetPartitionBy(d.Get("partition_by").([]interface{}))
+	tableResource.PrimaryKey = common.MapArrayInterfaceToArrayOfStrings(d.Get("primary_key").([]interface{}))
+	tableResource.SampleBy = d.Get("sample_by").(string)
+	tableResource.SetTTL(d.Get("ttl").([]interface{}))
+	tableResource.SetSettings(d.Get("settings").(map[string]interface{}))
+	tableResource.SetIndexes(d.Get("index").([]interface{}))
+	tableResource.SetProjections(d.Get("projection").([]interface{}))
 
 	if tableResource.Cluster != "" {
 		tableResource.Cluster = client.DefaultCluster
 	}
 
-	tableResource.Validate(diags)
+	diags = tableResource.Validate()
 	if diags.HasError() {
 		return diags
 	}
@@ -202,6 +449,101 @@ func resourceTableCreate(ctx context.Context, d *schema.ResourceData, meta any)
 	return diags
 }
 
+func resourceTableUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	conn := client.ClickhouseConnection
+	chTableService := CHTableService{CHConnection: conn}
+
+	var tableResource TableResource
+	tableResource.Database = d.Get("database").(string)
+	tableResource.Name = d.Get("name").(string)
+	tableResource.Cluster = d.Get("cluster").(string)
+	if tableResource.Cluster == "" {
+		tableResource.Cluster = client.DefaultCluster
+	}
+
+	// Removed indexes and projections are dropped before the columns they reference, new ones are added after
+	stateElements := TableResource{}
+	if d.HasChange("index") {
+		stateIndexes, planIndexes := d.GetChange("index")
+		stateElements.SetIndexes(stateIndexes.([]interface{}))
+		tableResource.SetIndexes(planIndexes.([]interface{}))
+
+		err := chTableService.DropTableIndexes(ctx, tableResource, stateElements.Indexes)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("projection") {
+		stateProjections, planProjections := d.GetChange("projection")
+		stateElements.SetProjections(stateProjections.([]interface{}))
+		tableResource.SetProjections(planProjections.([]interface{}))
+
+		err := chTableService.DropTableProjections(ctx, tableResource, stateElements.Projections)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("column") {
+		stateColumns, planColumns := d.GetChange("column")
+		stateTable := TableResource{Columns: stateColumns.([]interface{})}
+		planTable := TableResource{Columns: planColumns.([]interface{})}
+
+		err := chTableService.AlterTableColumns(ctx, tableResource, stateTable.GetColumnsResourceList(), planTable.GetColumnsResourceList())
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("ttl") {
+		tableResource.SetTTL(d.Get("ttl").([]interface{}))
+		err := chTableService.AlterTableTTL(ctx, tableResource)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("settings") {
+		stateSettings, planSettings := d.GetChange("settings")
+		stateTable := TableResource{}
+		stateTable.SetSettings(stateSettings.(map[string]interface{}))
+		tableResource.SetSettings(planSettings.(map[string]interface{}))
+
+		err := chTableService.AlterTableSettings(ctx, tableResource, stateTable.Settings)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("index") {
+		err := chTableService.AddTableIndexes(ctx, tableResource, stateElements.Indexes)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("projection") {
+		err := chTableService.AddTableProjections(ctx, tableResource, stateElements.Projections)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("comment") {
+		tableResource.Comment = common.GetComment(d.Get("comment").(string), d.Get("cluster").(string))
+		err := chTableService.AlterTableComment(ctx, tableResource)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
 func resourceTableDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := meta.(*common.ApiClient)
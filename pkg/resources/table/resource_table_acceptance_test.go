@@ -1,10 +1,12 @@
 package resourcetable_test
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
@@ -45,10 +47,538 @@ func TestAccResourceTable(t *testing.T) {
 					resource.TestCheckResourceAttr("clickhouse_table.table", "column.2.type", "DateTime"),
 				),
 			},
+			{
+				ResourceName:      "clickhouse_table.table",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceTable_UpdateColumns(t *testing.T) {
+	const tableName = "columns_update_test"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: tableConfigWithColumns(testResourceTableDatabaseName, tableName, "This is just a new table", [][]string{
+					{"key", "Int64"},
+					{"someCol", "String"},
+					{"counter", "UInt32"},
+					{"eventTime", "DateTime"},
+				}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.#", "4"),
+				),
+			},
+			// ADD, RENAME AND MODIFY COLUMNS IN PLACE
+			{
+				Config: tableConfigWithColumns(testResourceTableDatabaseName, tableName, "This is an updated table", [][]string{
+					{"key", "Int64"},
+					{"renamedCol", "String"},
+					{"counter", "UInt64"},
+					{"eventTime", "DateTime"},
+					{"newCol", "UInt32"},
+				}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "comment", "This is an updated table"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.#", "5"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.name", "renamedCol"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.2.type", "UInt64"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.4.name", "newCol"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.4.type", "UInt32"),
+				),
+			},
+			// DROP AND REORDER COLUMNS IN PLACE
+			{
+				Config: tableConfigWithColumns(testResourceTableDatabaseName, tableName, "This is an updated table", [][]string{
+					{"key", "Int64"},
+					{"newCol", "UInt32"},
+					{"eventTime", "DateTime"},
+				}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.#", "3"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.name", "newCol"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.2.name", "eventTime"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceTable_Engines(t *testing.T) {
+	const tableName = "engines_test"
+	engines := []struct {
+		engine string
+		params []string
+	}{
+		{"MergeTree", []string{}},
+		{"SummingMergeTree", []string{}},
+		{"AggregatingMergeTree", []string{}},
+		{"CollapsingMergeTree", []string{"sign"}},
+		{"VersionedCollapsingMergeTree", []string{"sign", "counter"}},
+		{"ReplacingMergeTree", []string{"counter"}},
+	}
+
+	var steps []resource.TestStep
+	for _, engine := range engines {
+		steps = append(steps, resource.TestStep{
+			Config: tableConfigWithEngine(testResourceTableDatabaseName, tableName, engine.engine, engine.params),
+			Check: resource.ComposeTestCheckFunc(
+				resource.TestCheckResourceAttr("clickhouse_table.table", "engine", engine.engine),
+				resource.TestCheckResourceAttr("clickhouse_table.table", "engine_params.#", fmt.Sprint(len(engine.params))),
+			),
+		})
+	}
+	steps = append(steps, resource.TestStep{
+		Config:      tableConfigWithEngine(testResourceTableDatabaseName, tableName, "CollapsingMergeTree", []string{}),
+		ExpectError: regexp.MustCompile("CollapsingMergeTree engine expects exactly 1 params, got 0"),
+	}, resource.TestStep{
+		Config:      tableConfigWithEngine(testResourceTableDatabaseName, tableName, "CollapsingMergeTree", []string{"notAColumn"}),
+		ExpectError: regexp.MustCompile("sign engine param 'notAColumn' is not a column"),
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps:     steps,
+	})
+}
+
+func tableConfigWithEngine(database string, tableName string, engine string, engineParams []string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+		comment = "this is a comment"
+	}
+
+	resource "clickhouse_table" "table" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_tableName_%"
+		engine = "%_engine_%"
+		engine_params = [%_engineParams_%]
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "Int64"
+		}
+		column {
+			name = "sign"
+			type = "Int8"
+		}
+		column {
+			name = "counter"
+			type = "UInt64"
+		}
+}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_tableName_%", tableName, -1)
+	s = strings.Replace(s, "%_engine_%", engine, -1)
+	s = strings.Replace(s, "%_engineParams_%", strings.Join(common.Quote(engineParams), ", "), -1)
+	return s
+}
+
+func TestAccResourceTable_ColumnAttributes(t *testing.T) {
+	const tableName = "column_attributes_test"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: tableConfigWithColumnAttributes(testResourceTableDatabaseName, tableName, "Delta, ZSTD", "now()", "event time"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.default_kind", "DEFAULT"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.default_expression", "now()"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.comment", "event time"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.codec", "Delta, ZSTD"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.2.default_kind", "MATERIALIZED"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.2.default_expression", "toDate(eventTime)"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.3.default_kind", "ALIAS"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.3.ttl", ""),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.4.ttl", "eventTime + INTERVAL 1 DAY"),
+				),
+			},
+			// UPDATE AND REMOVE COLUMN ATTRIBUTES IN PLACE
+			{
+				Config: tableConfigWithColumnAttributes(testResourceTableDatabaseName, tableName, "", "now() - 1", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.default_expression", "now() - 1"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.comment", ""),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.1.codec", ""),
+				),
+			},
 		},
 	})
 }
 
+func tableConfigWithColumnAttributes(database string, tableName string, codec string, defaultExpression string, comment string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+		comment = "this is a comment"
+	}
+
+	resource "clickhouse_table" "table" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_tableName_%"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "Int64"
+		}
+		column {
+			name = "eventTime"
+			type = "DateTime"
+			default_kind = "DEFAULT"
+			default_expression = "%_defaultExpression_%"
+			codec = "%_codec_%"
+			comment = "%_comment_%"
+		}
+		column {
+			name = "eventDate"
+			type = "Date"
+			default_kind = "MATERIALIZED"
+			default_expression = "toDate(eventTime)"
+		}
+		column {
+			name = "keyAlias"
+			type = "Int64"
+			default_kind = "ALIAS"
+			default_expression = "key"
+		}
+		column {
+			name = "payload"
+			type = "String"
+			ttl = "eventTime + INTERVAL 1 DAY"
+		}
+}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_tableName_%", tableName, -1)
+	s = strings.Replace(s, "%_codec_%", codec, -1)
+	s = strings.Replace(s, "%_defaultExpression_%", defaultExpression, -1)
+	s = strings.Replace(s, "%_comment_%", comment, -1)
+	return s
+}
+
+func TestAccResourceTable_StorageClauses(t *testing.T) {
+	const tableName = "storage_clauses_test"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: tableConfigWithStorageClauses(testResourceTableDatabaseName, tableName, "INTERVAL 1 MONTH", "8192"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "primary_key.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "sample_by", "userId"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "ttl.#", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "ttl.0.action", "DELETE"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "ttl.1.action", "GROUP BY"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "settings.index_granularity", "8192"),
+				),
+			},
+			// MODIFY TTL AND SETTINGS IN PLACE
+			{
+				Config: tableConfigWithStorageClauses(testResourceTableDatabaseName, tableName, "INTERVAL 2 MONTH", "4096"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "ttl.0.expression", "eventTime + INTERVAL 2 MONTH"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "settings.index_granularity", "4096"),
+				),
+			},
+			{
+				Config:      strings.Replace(tableConfigWithStorageClauses(testResourceTableDatabaseName, tableName, "INTERVAL 2 MONTH", "4096"), `sample_by = "userId"`, `sample_by = "eventTime"`, 1),
+				ExpectError: regexp.MustCompile("sample_by 'eventTime' must be part of the primary key"),
+			},
+		},
+	})
+}
+
+func tableConfigWithStorageClauses(database string, tableName string, interval string, indexGranularity string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+		comment = "this is a comment"
+	}
+
+	resource "clickhouse_table" "table" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_tableName_%"
+		engine = "MergeTree"
+		order_by = ["key", "userId", "eventTime"]
+		primary_key = ["key", "userId"]
+		sample_by = "userId"
+		column {
+			name = "key"
+			type = "Int64"
+		}
+		column {
+			name = "userId"
+			type = "UInt64"
+		}
+		column {
+			name = "eventTime"
+			type = "DateTime"
+		}
+		column {
+			name = "value"
+			type = "UInt64"
+		}
+		ttl {
+			expression = "eventTime + %_interval_%"
+		}
+		ttl {
+			expression = "eventTime + INTERVAL 1 WEEK"
+			action = "GROUP BY"
+			group_by = "key, userId"
+			set = "value = sum(value)"
+		}
+		settings = {
+			index_granularity = "%_indexGranularity_%"
+		}
+}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_tableName_%", tableName, -1)
+	s = strings.Replace(s, "%_interval_%", interval, -1)
+	s = strings.Replace(s, "%_indexGranularity_%", indexGranularity, -1)
+	return s
+}
+
+func TestAccResourceTable_IndexesAndProjections(t *testing.T) {
+	const tableName = "indexes_projections_test"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: tableConfigWithIndexesAndProjections(testResourceTableDatabaseName, tableName, "4", "SELECT * ORDER BY userId"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "index.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "index.0.type", "bloom_filter"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "index.0.granularity", "4"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "projection.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "projection.0.query", "SELECT * ORDER BY userId"),
+				),
+			},
+			// DROP AND ADD INDEX AND PROJECTION IN PLACE
+			{
+				Config: tableConfigWithIndexesAndProjections(testResourceTableDatabaseName, tableName, "2", "SELECT userId, count() GROUP BY userId"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "index.0.granularity", "2"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "projection.0.query", "SELECT userId, count() GROUP BY userId"),
+				),
+			},
+			// DROP COLUMN ALONG WITH THE INDEX AND PROJECTION REFERENCING IT
+			{
+				Config: tableConfigWithoutIndexedColumn(testResourceTableDatabaseName, tableName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "column.#", "1"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "index.#", "0"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "projection.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func tableConfigWithoutIndexedColumn(database string, tableName string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+		comment = "this is a comment"
+	}
+
+	resource "clickhouse_table" "table" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_tableName_%"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "Int64"
+		}
+}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_tableName_%", tableName, -1)
+	return s
+}
+
+func tableConfigWithIndexesAndProjections(database string, tableName string, granularity string, projectionQuery string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+		comment = "this is a comment"
+	}
+
+	resource "clickhouse_table" "table" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_tableName_%"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "Int64"
+		}
+		column {
+			name = "userId"
+			type = "String"
+		}
+		index {
+			name = "idx_user"
+			expression = "userId"
+			type = "bloom_filter"
+			granularity = %_granularity_%
+			materialize = true
+		}
+		projection {
+			name = "by_user"
+			query = "%_projectionQuery_%"
+			materialize = true
+		}
+}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_tableName_%", tableName, -1)
+	s = strings.Replace(s, "%_granularity_%", granularity, -1)
+	s = strings.Replace(s, "%_projectionQuery_%", projectionQuery, -1)
+	return s
+}
+
+func TestAccResourceTable_KeyExpressions(t *testing.T) {
+	const tableName = "key_expressions_test"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: tableConfigWithKeyExpressions(testResourceTableDatabaseName, tableName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_table.table", "order_by.0", "cityHash64(userId)"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "order_by.1", "eventTime"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "partition_by.0.by", "tenantId % 4"),
+					resource.TestCheckResourceAttr("clickhouse_table.table", "partition_by.1.partition_function", "toMonday"),
+				),
+			},
+			{
+				Config:   tableConfigWithKeyExpressions(testResourceTableDatabaseName, tableName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func tableConfigWithKeyExpressions(database string, tableName string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+		comment = "this is a comment"
+	}
+
+	resource "clickhouse_table" "table" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_tableName_%"
+		engine = "MergeTree"
+		order_by = ["cityHash64(userId)", "eventTime"]
+		column {
+			name = "tenantId"
+			type = "UInt32"
+		}
+		column {
+			name = "userId"
+			type = "String"
+		}
+		column {
+			name = "eventTime"
+			type = "DateTime"
+		}
+		partition_by {
+			by = "tenantId % 4"
+		}
+		partition_by {
+			by = "eventTime"
+			partition_function = "toMonday"
+		}
+}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_tableName_%", tableName, -1)
+	return s
+}
+
+func TestAccResourceTable_Drift(t *testing.T) {
+	const tableName = "drift_test"
+	config := tableConfigWithColumns(testResourceTableDatabaseName, tableName, "this is a comment", [][]string{{"key", "Int64"}, {"eventTime", "DateTime"}})
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// COMMENT CHANGED OUTSIDE TERRAFORM IS REPORTED AND RESTORED IN PLACE
+			{
+				PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("ALTER TABLE %s.%s MODIFY COMMENT 'manual comment'", testResourceTableDatabaseName, tableName)),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("clickhouse_table.table", "comment", "this is a comment"),
+			},
+			// SORTING KEY CHANGED OUTSIDE TERRAFORM IS REPORTED
+			{
+				PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN extra Int64, MODIFY ORDER BY (key, extra)", testResourceTableDatabaseName, tableName)),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// TABLE DROPPED OUTSIDE TERRAFORM IS RECREATED
+			{
+				PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("DROP TABLE %s.%s", testResourceTableDatabaseName, tableName)),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func tableConfigWithColumns(database string, tableName string, comment string, columns [][]string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+		comment = "this is a comment"
+	}
+
+	resource "clickhouse_table" "table" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_tableName_%"
+		engine = "ReplacingMergeTree"
+		engine_params = ["eventTime"]
+		order_by = ["key"]
+		%_columns_%
+		comment = "%_comment_%"
+}`
+
+	var columnBlocks []string
+	for _, column := range columns {
+		columnBlocks = append(columnBlocks, fmt.Sprintf("column {\n\t\t\tname = %q\n\t\t\ttype = %q\n\t\t}", column[0], column[1]))
+	}
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_tableName_%", tableName, -1)
+	s = strings.Replace(s, "%_comment_%", comment, -1)
+	s = strings.Replace(s, "%_columns_%", strings.Join(columnBlocks, "\n\t\t"), -1)
+	return s
+}
+
 func tableConfigWithName(database string, tableName string) string {
 	s := `
 	resource "clickhouse_db" "new_db_resource" {
@@ -2,18 +2,21 @@ package resourcetable
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"sort"
+	"strings"
 )
 
 type CHTableService struct {
-	CHConnection *driver.Conn
+	CHConnection common.Executor
 }
 
 func (ts *CHTableService) GetDBTables(ctx context.Context, database string) ([]CHTable, error) {
-	query := fmt.Sprintf("SELECT database, name FROM system.tables where database = '%s'", database)
-	rows, err := (*ts.CHConnection).Query(ctx, query)
+	query := "SELECT database, name, engine FROM system.tables where database = ?"
+	rows, err := ts.CHConnection.Query(ctx, query, database)
 
 	if err != nil {
 		return nil, fmt.Errorf("reading tables from Clickhouse: %v", err)
@@ -26,6 +29,9 @@ func (ts *CHTableService) GetDBTables(ctx context.Context, database string) ([]C
 		if err != nil {
 			return nil, fmt.Errorf("scanning Clickhouse table row: %v", err)
 		}
+		if IsViewEngine(table.Engine) {
+			continue
+		}
 		tables = append(tables, table)
 	}
 
@@ -33,8 +39,8 @@ func (ts *CHTableService) GetDBTables(ctx context.Context, database string) ([]C
 }
 
 func (ts *CHTableService) GetTable(ctx context.Context, database string, table string) (*CHTable, error) {
-	query := fmt.Sprintf("SELECT database, name, engine_full, engine, comment FROM system.tables where database = '%s' and name = '%s'", database, table)
-	row := (*ts.CHConnection).QueryRow(ctx, query)
+	query := "SELECT database, name, engine_full, engine, comment, sorting_key, partition_key, primary_key, sampling_key, create_table_query FROM system.tables where database = ? and name = ?"
+	row := ts.CHConnection.QueryRow(ctx, query, database, table)
 
 	if row.Err() != nil {
 		return nil, fmt.Errorf("reading table from Clickhouse: %v", row.Err())
@@ -42,6 +48,9 @@ func (ts *CHTableService) GetTable(ctx context.Context, database string, table s
 
 	var chTable CHTable
 	err := row.ScanStruct(&chTable)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("scanning Clickhouse table row: %v", err)
 	}
@@ -51,16 +60,17 @@ func (ts *CHTableService) GetTable(ctx context.Context, database string, table s
 		return nil, fmt.Errorf("getting columns for Clickhouse table: %v", err)
 	}
 
+	chTable.Indexes, err = ts.getTableIndexes(ctx, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("getting indexes for Clickhouse table: %v", err)
+	}
+
 	return &chTable, nil
 }
 
 func (ts *CHTableService) getTableColumns(ctx context.Context, database string, table string) ([]CHColumn, error) {
-	query := fmt.Sprintf(
-		"SELECT database, table, name, type FROM system.columns WHERE database = '%s' AND table = '%s'",
-		database,
-		table,
-	)
-	rows, err := (*ts.CHConnection).Query(ctx, query)
+	query := "SELECT database, table, name, type, default_kind, default_expression, compression_codec, comment FROM system.columns WHERE database = ? AND table = ? ORDER BY position"
+	rows, err := ts.CHConnection.Query(ctx, query, database, table)
 
 	if err != nil {
 		return nil, fmt.Errorf("reading columns from Clickhouse: %v", err)
@@ -78,9 +88,29 @@ func (ts *CHTableService) getTableColumns(ctx context.Context, database string,
 	return chColumns, nil
 }
 
+func (ts *CHTableService) getTableIndexes(ctx context.Context, database string, table string) ([]CHIndex, error) {
+	query := "SELECT name, expr, type_full, granularity FROM system.data_skipping_indices WHERE database = ? AND table = ?"
+	rows, err := ts.CHConnection.Query(ctx, query, database, table)
+
+	if err != nil {
+		return nil, fmt.Errorf("reading indexes from Clickhouse: %v", err)
+	}
+
+	var chIndexes []CHIndex
+	for rows.Next() {
+		var index CHIndex
+		err := rows.ScanStruct(&index)
+		if err != nil {
+			return nil, fmt.Errorf("scanning Clickhouse index row: %v", err)
+		}
+		chIndexes = append(chIndexes, index)
+	}
+	return chIndexes, nil
+}
+
 func (ts *CHTableService) CreateTable(ctx context.Context, tableResource TableResource) error {
 	query := buildCreateOnClusterSentence(tableResource)
-	err := (*ts.CHConnection).Exec(ctx, query)
+	err := ts.CHConnection.Exec(ctx, query)
 	if err != nil {
 		return fmt.Errorf("creating Clickhouse table: %v", err)
 	}
@@ -88,10 +118,108 @@ func (ts *CHTableService) CreateTable(ctx context.Context, tableResource TableRe
 }
 
 func (ts *CHTableService) DeleteTable(ctx context.Context, tableResource TableResource) error {
-	query := fmt.Sprintf("DROP TABLE %s.%s %s", tableResource.Database, tableResource.Name, common.GetClusterStatement(tableResource.Cluster))
-	err := (*ts.CHConnection).Exec(ctx, query)
+	query := fmt.Sprintf("DROP TABLE %s %s", common.QualifiedName(tableResource.Database, tableResource.Name), common.GetClusterStatement(tableResource.Cluster))
+	err := ts.CHConnection.Exec(ctx, query)
 	if err != nil {
 		return fmt.Errorf("deleting Clickhouse table: %v", err)
 	}
 	return nil
 }
+
+func (ts *CHTableService) AlterTableColumns(ctx context.Context, tableResource TableResource, stateColumns []ColumnResource, planColumns []ColumnResource) error {
+	for _, action := range buildAlterColumnsActions(stateColumns, planColumns) {
+		query := buildAlterTableSentence(tableResource, action)
+		err := ts.CHConnection.Exec(ctx, query)
+		if err != nil {
+			return fmt.Errorf("altering Clickhouse table columns: %v", err)
+		}
+	}
+	return nil
+}
+
+func (ts *CHTableService) AlterTableComment(ctx context.Context, tableResource TableResource) error {
+	query := buildAlterTableSentence(tableResource, fmt.Sprintf("MODIFY COMMENT %s", common.QuoteString(tableResource.Comment)))
+	err := ts.CHConnection.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("altering Clickhouse table comment: %v", err)
+	}
+	return nil
+}
+
+func (ts *CHTableService) AlterTableTTL(ctx context.Context, tableResource TableResource) error {
+	action := "REMOVE TTL"
+	if len(tableResource.TTL) > 0 {
+		action = fmt.Sprintf("MODIFY TTL %s", buildTTLClause(tableResource.TTL))
+	}
+	err := ts.CHConnection.Exec(ctx, buildAlterTableSentence(tableResource, action))
+	if err != nil {
+		return fmt.Errorf("altering Clickhouse table TTL: %v", err)
+	}
+	return nil
+}
+
+func (ts *CHTableService) AlterTableSettings(ctx context.Context, tableResource TableResource, stateSettings map[string]string) error {
+	var resetSettings []string
+	for key := range stateSettings {
+		if _, ok := tableResource.Settings[key]; !ok {
+			resetSettings = append(resetSettings, key)
+		}
+	}
+	modifiedSettings := make(map[string]string)
+	for key, value := range tableResource.Settings {
+		if stateValue, ok := stateSettings[key]; !ok || stateValue != value {
+			modifiedSettings[key] = value
+		}
+	}
+
+	if len(modifiedSettings) > 0 {
+		action := fmt.Sprintf("MODIFY SETTING %s", strings.Join(buildSettingsItems(modifiedSettings), ", "))
+		err := ts.CHConnection.Exec(ctx, buildAlterTableSentence(tableResource, action))
+		if err != nil {
+			return fmt.Errorf("altering Clickhouse table settings: %v", err)
+		}
+	}
+	if len(resetSettings) > 0 {
+		sort.Strings(resetSettings)
+		action := fmt.Sprintf("RESET SETTING %s", strings.Join(common.QuoteIdentifiers(resetSettings), ", "))
+		err := ts.CHConnection.Exec(ctx, buildAlterTableSentence(tableResource, action))
+		if err != nil {
+			return fmt.Errorf("resetting Clickhouse table settings: %v", err)
+		}
+	}
+	return nil
+}
+
+// DropTableIndexes drops the state indexes removed or changed in tableResource, before its columns are altered.
+func (ts *CHTableService) DropTableIndexes(ctx context.Context, tableResource TableResource, stateIndexes []IndexResource) error {
+	dropActions, _ := buildAlterIndexesActions(stateIndexes, tableResource.Indexes)
+	return ts.execAlterActions(ctx, tableResource, dropActions, "indexes")
+}
+
+// AddTableIndexes adds the indexes of tableResource that are new or changed, once its columns are altered.
+func (ts *CHTableService) AddTableIndexes(ctx context.Context, tableResource TableResource, stateIndexes []IndexResource) error {
+	_, addActions := buildAlterIndexesActions(stateIndexes, tableResource.Indexes)
+	return ts.execAlterActions(ctx, tableResource, addActions, "indexes")
+}
+
+// DropTableProjections drops the state projections removed or changed in tableResource, before its columns are altered.
+func (ts *CHTableService) DropTableProjections(ctx context.Context, tableResource TableResource, stateProjections []ProjectionResource) error {
+	dropActions, _ := buildAlterProjectionsActions(stateProjections, tableResource.Projections)
+	return ts.execAlterActions(ctx, tableResource, dropActions, "projections")
+}
+
+// AddTableProjections adds the projections of tableResource that are new or changed, once its columns are altered.
+func (ts *CHTableService) AddTableProjections(ctx context.Context, tableResource TableResource, stateProjections []ProjectionResource) error {
+	_, addActions := buildAlterProjectionsActions(stateProjections, tableResource.Projections)
+	return ts.execAlterActions(ctx, tableResource, addActions, "projections")
+}
+
+func (ts *CHTableService) execAlterActions(ctx context.Context, tableResource TableResource, actions []string, altered string) error {
+	for _, action := range actions {
+		err := ts.CHConnection.Exec(ctx, buildAlterTableSentence(tableResource, action))
+		if err != nil {
+			return fmt.Errorf("altering Clickhouse table %s: %v", altered, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package resourcetable
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+)
+
+func TestGetTable(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.tables", chfake.Row{
+		"database":      "db",
+		"name":          "events",
+		"engine":        "MergeTree",
+		"engine_full":   "MergeTree ORDER BY id",
+		"comment":       "events table",
+		"sorting_key":   "id",
+		"partition_key": "",
+	}, chfake.Row{"database": "db", "name": "other", "engine": "Log"})
+	fake.AddRows("system.columns",
+		chfake.Row{"database": "db", "table": "events", "name": "id", "type": "UInt64"},
+		chfake.Row{"database": "db", "table": "events", "name": "at", "type": "DateTime", "default_kind": "DEFAULT", "default_expression": "now()"},
+		chfake.Row{"database": "db", "table": "other", "name": "value", "type": "String"},
+	)
+	fake.AddRows("system.data_skipping_indices", chfake.Row{"database": "db", "table": "events", "name": "idx", "expr": "at", "type_full": "minmax", "granularity": uint64(2)})
+	service := CHTableService{CHConnection: fake}
+
+	table, err := service.GetTable(context.Background(), "db", "events")
+	if err != nil {
+		t.Fatalf("GetTable failed: %v", err)
+	}
+	expected := CHTable{
+		Database:   "db",
+		Name:       "events",
+		Engine:     "MergeTree",
+		EngineFull: "MergeTree ORDER BY id",
+		Comment:    "events table",
+		SortingKey: "id",
+		Columns: []CHColumn{
+			{Database: "db", Table: "events", Name: "id", Type: "UInt64"},
+			{Database: "db", Table: "events", Name: "at", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()"},
+		},
+		Indexes: []CHIndex{{Name: "idx", Expression: "at", Type: "minmax", Granularity: 2}},
+	}
+	if !reflect.DeepEqual(*table, expected) {
+		t.Errorf("GetTable() = %+v, expected %+v", *table, expected)
+	}
+
+	missing, err := service.GetTable(context.Background(), "db", "missing")
+	if err != nil || missing != nil {
+		t.Errorf("GetTable() of a missing table = %v, %v, expected nil", missing, err)
+	}
+}
+
+func TestGetDBTables(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.tables",
+		chfake.Row{"database": "db", "name": "a"},
+		chfake.Row{"database": "other", "name": "b"},
+		chfake.Row{"database": "db", "name": "c"},
+	)
+	service := CHTableService{CHConnection: fake}
+
+	tables, err := service.GetDBTables(context.Background(), "db")
+	if err != nil {
+		t.Fatalf("GetDBTables failed: %v", err)
+	}
+	if len(tables) != 2 || tables[0].Name != "a" || tables[1].Name != "c" {
+		t.Errorf("unexpected tables %+v", tables)
+	}
+
+	fake.FailOn("system.tables", errors.New("connection refused"))
+	if _, err := service.GetDBTables(context.Background(), "db"); err == nil {
+		t.Errorf("GetDBTables expected to fail")
+	}
+}
+
+func TestTableStatements(t *testing.T) {
+	table := TableResource{
+		Database: "db",
+		Name:     "events",
+		Cluster:  "cluster",
+		Engine:   "MergeTree",
+		Comment:  "it's a table",
+		OrderBy:  []string{"id"},
+		Columns: []interface{}{
+			map[string]interface{}{"name": "id", "type": "UInt64", "default_kind": "", "default_expression": "", "codec": "", "ttl": "", "comment": ""},
+		},
+		Settings: map[string]string{"index_granularity": "1024", "merge_with_ttl_timeout": "3600"},
+	}
+	fake := chfake.New()
+	service := CHTableService{CHConnection: fake}
+	ctx := context.Background()
+
+	if err := service.CreateTable(ctx, table); err != nil {
+		t.Fatalf("CreateTable failed: %v", err)
+	}
+	if err := service.AlterTableComment(ctx, table); err != nil {
+		t.Fatalf("AlterTableComment failed: %v", err)
+	}
+	if err := service.AlterTableSettings(ctx, table, map[string]string{"index_granularity": "1024", "min_bytes_for_wide_part": "0"}); err != nil {
+		t.Fatalf("AlterTableSettings failed: %v", err)
+	}
+	if err := service.DeleteTable(ctx, table); err != nil {
+		t.Fatalf("DeleteTable failed: %v", err)
+	}
+
+	if len(fake.Statements) != 5 {
+		t.Fatalf("unexpected statements %q", fake.Statements)
+	}
+	create := fake.Statements[0]
+	if !strings.HasPrefix(create, "CREATE TABLE `db`.`events` ON CLUSTER `cluster` (") || !strings.Contains(create, "ENGINE = MergeTree() ORDER BY id") {
+		t.Errorf("unexpected create statement %q", create)
+	}
+	expected := []string{
+		"ALTER TABLE `db`.`events` ON CLUSTER `cluster` MODIFY COMMENT 'it\\'s a table'",
+		"ALTER TABLE `db`.`events` ON CLUSTER `cluster` MODIFY SETTING `merge_with_ttl_timeout` = 3600",
+		"ALTER TABLE `db`.`events` ON CLUSTER `cluster` RESET SETTING `min_bytes_for_wide_part`",
+		"DROP TABLE `db`.`events` ON CLUSTER `cluster`",
+	}
+	if !reflect.DeepEqual(fake.Statements[1:], expected) {
+		t.Errorf("unexpected statements %q, expected %q", fake.Statements[1:], expected)
+	}
+}
@@ -3,58 +3,380 @@ package resourcetable
 import (
 	"fmt"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"sort"
+	"strconv"
 	"strings"
 )
 
+func buildColumnDefinition(col ColumnResource) string {
+	definition := fmt.Sprintf("%s %s", common.QuoteIdentifier(col.Name), col.Type)
+	if col.DefaultKind != "" {
+		definition = strings.TrimSpace(fmt.Sprintf("%s %s %s", definition, col.DefaultKind, col.DefaultExpression))
+	}
+	if col.Comment != "" {
+		definition = fmt.Sprintf("%s COMMENT %s", definition, common.QuoteString(col.Comment))
+	}
+	if col.Codec != "" {
+		definition = fmt.Sprintf("%s CODEC(%s)", definition, unwrapCodec(col.Codec))
+	}
+	if col.TTL != "" {
+		definition = fmt.Sprintf("%s TTL %s", definition, col.TTL)
+	}
+	return definition
+}
+
 func buildColumnsSentence(cols []ColumnResource) []string {
 	outColumn := make([]string, 0)
 	for _, col := range cols {
-		outColumn = append(outColumn, fmt.Sprintf("\t %s %s", col.Name, col.Type))
+		outColumn = append(outColumn, fmt.Sprintf("\t %s", buildColumnDefinition(col)))
 	}
 	return outColumn
 }
 
+// removedColumnProperties returns the properties set in state but not in plan, along with the column without them.
+func removedColumnProperties(stateCol ColumnResource, planCol ColumnResource) ([]string, ColumnResource) {
+	var removed []string
+	if stateCol.DefaultKind != "" && planCol.DefaultKind == "" {
+		removed = append(removed, stateCol.DefaultKind)
+		stateCol.DefaultKind, stateCol.DefaultExpression = "", ""
+	}
+	if stateCol.Comment != "" && planCol.Comment == "" {
+		removed = append(removed, "COMMENT")
+		stateCol.Comment = ""
+	}
+	if stateCol.Codec != "" && planCol.Codec == "" {
+		removed = append(removed, "CODEC")
+		stateCol.Codec = ""
+	}
+	if stateCol.TTL != "" && planCol.TTL == "" {
+		removed = append(removed, "TTL")
+		stateCol.TTL = ""
+	}
+	return removed, stateCol
+}
+
+func buildPartitionByItem(partitionBy PartitionByResource) string {
+	if partitionBy.PartitionFunction == "" {
+		return partitionBy.By
+	}
+	return fmt.Sprintf("%v(%v)", partitionBy.PartitionFunction, partitionBy.By)
+}
+
+func buildPartitionByClause(partitionBy []PartitionByResource) string {
+	partitionBySentenceItems := make([]string, 0)
+	for _, partitionByItem := range partitionBy {
+		partitionBySentenceItems = append(partitionBySentenceItems, buildPartitionByItem(partitionByItem))
+	}
+	return strings.Join(partitionBySentenceItems, ", ")
+}
+
 func buildPartitionBySentence(partitionBy []PartitionByResource) string {
 	if len(partitionBy) > 0 {
-		partitionBySentenceItems := make([]string, 0)
-		for _, partitionByItem := range partitionBy {
-			if partitionByItem.PartitionFunction == "" {
-				partitionBySentenceItems = append(partitionBySentenceItems, partitionByItem.By)
-			} else {
-				partitionBySentenceItems = append(partitionBySentenceItems, fmt.Sprintf("%v(%v)", partitionByItem.PartitionFunction, partitionByItem.By))
-			}
-		}
-		return fmt.Sprintf("PARTITION BY %v", strings.Join(partitionBySentenceItems, ", "))
+		return fmt.Sprintf("PARTITION BY %v", buildPartitionByClause(partitionBy))
 	}
 	return ""
 }
 
-func buildOrderBySentence(orderBy []string) string {
+func buildOrderBySentence(engine string, orderBy []string) string {
 	if len(orderBy) > 0 {
 		return fmt.Sprintf("ORDER BY %v", strings.Join(orderBy, ", "))
 	}
+	if IsMergeTreeEngine(engine) {
+		// MergeTree engines require a sorting key, an empty tuple means no sorting at all
+		return "ORDER BY tuple()"
+	}
+	return ""
+}
+
+func buildPrimaryKeySentence(primaryKey []string) string {
+	if len(primaryKey) > 0 {
+		return fmt.Sprintf("PRIMARY KEY %v", strings.Join(primaryKey, ", "))
+	}
+	return ""
+}
+
+func buildSampleBySentence(sampleBy string) string {
+	if sampleBy != "" {
+		return fmt.Sprintf("SAMPLE BY %v", sampleBy)
+	}
+	return ""
+}
+
+func buildTTLItem(ttl TTLResource) string {
+	item := ttl.Expression
+	switch ttl.Action {
+	case "TO DISK", "TO VOLUME":
+		item = fmt.Sprintf("%s %s %s", item, ttl.Action, common.QuoteString(ttl.Target))
+	case "GROUP BY":
+	default:
+		item = fmt.Sprintf("%s DELETE", item)
+	}
+	if ttl.Where != "" {
+		item = fmt.Sprintf("%s WHERE %s", item, ttl.Where)
+	}
+	if ttl.Action == "GROUP BY" {
+		item = fmt.Sprintf("%s GROUP BY %s", item, ttl.GroupBy)
+		if ttl.Set != "" {
+			item = fmt.Sprintf("%s SET %s", item, ttl.Set)
+		}
+	}
+	return item
+}
+
+func buildTTLClause(ttls []TTLResource) string {
+	items := make([]string, 0)
+	for _, ttl := range ttls {
+		items = append(items, buildTTLItem(ttl))
+	}
+	return strings.Join(items, ", ")
+}
+
+func buildTTLSentence(ttls []TTLResource) string {
+	if len(ttls) > 0 {
+		return fmt.Sprintf("TTL %v", buildTTLClause(ttls))
+	}
+	return ""
+}
+
+func buildSettingValue(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil || isStringLiteral(value) {
+		return value
+	}
+	return common.QuoteString(value)
+}
+
+func buildSettingsItems(settings map[string]string) []string {
+	var keys []string
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, 0)
+	for _, key := range keys {
+		items = append(items, fmt.Sprintf("%s = %s", common.QuoteIdentifier(key), buildSettingValue(settings[key])))
+	}
+	return items
+}
+
+func buildSettingsSentence(settings map[string]string) string {
+	if len(settings) > 0 {
+		return fmt.Sprintf("SETTINGS %v", strings.Join(buildSettingsItems(settings), ", "))
+	}
 	return ""
 }
 
+func buildIndexDefinition(index IndexResource) string {
+	return fmt.Sprintf("INDEX %s %s TYPE %s GRANULARITY %d", common.QuoteIdentifier(index.Name), index.Expression, index.Type, index.Granularity)
+}
+
+func buildProjectionDefinition(projection ProjectionResource) string {
+	return fmt.Sprintf("PROJECTION %s (%s)", common.QuoteIdentifier(projection.Name), projection.Query)
+}
+
+func buildIndexesSentence(indexes []IndexResource, projections []ProjectionResource) []string {
+	outIndexes := make([]string, 0)
+	for _, index := range indexes {
+		outIndexes = append(outIndexes, fmt.Sprintf("\t %s", buildIndexDefinition(index)))
+	}
+	for _, projection := range projections {
+		outIndexes = append(outIndexes, fmt.Sprintf("\t %s", buildProjectionDefinition(projection)))
+	}
+	return outIndexes
+}
+
+// BuildEngineSentence renders the ENGINE clause along with the storage clauses following it (ORDER BY,
+// PARTITION BY, TTL, SETTINGS...), shared with the resources creating tables, like materialized views.
+func BuildEngineSentence(resource TableResource) string {
+	return fmt.Sprintf(
+		"ENGINE = %v(%v) %s %s %s %s %s %s",
+		resource.Engine,
+		strings.Join(resource.EngineParams, ", "),
+		buildOrderBySentence(resource.Engine, resource.OrderBy),
+		buildPartitionBySentence(resource.PartitionBy),
+		buildPrimaryKeySentence(resource.PrimaryKey),
+		buildSampleBySentence(resource.SampleBy),
+		buildTTLSentence(resource.TTL),
+		buildSettingsSentence(resource.Settings),
+	)
+}
+
+// BuildColumnsStatement renders the parenthesized list of column definitions of a CREATE statement.
+func BuildColumnsStatement(columns []ColumnResource) string {
+	return "(" + strings.Join(buildColumnsSentence(columns), ",\n") + ")\n"
+}
+
 func buildCreateOnClusterSentence(resource TableResource) (query string) {
 	columnsStatement := ""
 	if len(resource.Columns) > 0 {
 		columnsList := buildColumnsSentence(resource.GetColumnsResourceList())
+		columnsList = append(columnsList, buildIndexesSentence(resource.Indexes, resource.Projections)...)
 		columnsStatement = "(" + strings.Join(columnsList, ",\n") + ")\n"
 	}
 
 	clusterStatement := common.GetClusterStatement(resource.Cluster)
 
 	return fmt.Sprintf(
-		"CREATE TABLE %v.%v %v %v ENGINE = %v(%v) %s %s COMMENT '%s'",
-		resource.Database,
-		resource.Name,
+		"CREATE TABLE %v %v %v %s COMMENT %s",
+		common.QualifiedName(resource.Database, resource.Name),
 		clusterStatement,
 		columnsStatement,
-		resource.Engine,
-		strings.Join(resource.EngineParams, ", "),
-		buildOrderBySentence(resource.OrderBy),
-		buildPartitionBySentence(resource.PartitionBy),
-		resource.Comment,
+		BuildEngineSentence(resource),
+		common.QuoteString(resource.Comment),
+	)
+}
+
+func buildColumnPositionSentence(cols []ColumnResource, index int) string {
+	if index == 0 {
+		return "FIRST"
+	}
+	return fmt.Sprintf("AFTER %s", common.QuoteIdentifier(cols[index-1].Name))
+}
+
+// buildAlterColumnsActions computes the ALTER TABLE actions that turn the state columns into the plan columns.
+// A column keeping its position and its definition while both its old name disappears and its new name is unknown is
+// considered renamed, so the data it holds is preserved. A column changing its type or another attribute as well is
+// dropped and added again, as it may be a different column.
+func buildAlterColumnsActions(stateColumns []ColumnResource, planColumns []ColumnResource) []string {
+	actions := make([]string, 0)
+
+	stateByName := make(map[string]ColumnResource)
+	for _, col := range stateColumns {
+		stateByName[col.Name] = col
+	}
+	planByName := make(map[string]ColumnResource)
+	for _, col := range planColumns {
+		planByName[col.Name] = col
+	}
+
+	current := make([]ColumnResource, len(stateColumns))
+	copy(current, stateColumns)
+
+	for i := range current {
+		if i >= len(planColumns) {
+			break
+		}
+		_, stillPlanned := planByName[current[i].Name]
+		_, alreadyExists := stateByName[planColumns[i].Name]
+		renamed := current[i]
+		renamed.Name = planColumns[i].Name
+		if !stillPlanned && !alreadyExists && renamed == planColumns[i] {
+			actions = append(actions, fmt.Sprintf("RENAME COLUMN %s TO %s", common.QuoteIdentifier(current[i].Name), common.QuoteIdentifier(planColumns[i].Name)))
+			current[i].Name = planColumns[i].Name
+		}
+	}
+
+	kept := make([]ColumnResource, 0)
+	for _, col := range current {
+		if _, ok := planByName[col.Name]; !ok {
+			actions = append(actions, fmt.Sprintf("DROP COLUMN %s", common.QuoteIdentifier(col.Name)))
+			continue
+		}
+		kept = append(kept, col)
+	}
+	current = kept
+
+	for i, planCol := range planColumns {
+		position := -1
+		for j, col := range current {
+			if col.Name == planCol.Name {
+				position = j
+				break
+			}
+		}
+
+		if position == -1 {
+			actions = append(actions, fmt.Sprintf("ADD COLUMN %s %s", buildColumnDefinition(planCol), buildColumnPositionSentence(planColumns, i)))
+			current = append(current[:i], append([]ColumnResource{planCol}, current[i:]...)...)
+			continue
+		}
+
+		removed, remaining := removedColumnProperties(current[position], planCol)
+		for _, property := range removed {
+			actions = append(actions, fmt.Sprintf("MODIFY COLUMN %s REMOVE %s", common.QuoteIdentifier(planCol.Name), property))
+		}
+
+		if position != i {
+			actions = append(actions, fmt.Sprintf("MODIFY COLUMN %s %s", buildColumnDefinition(planCol), buildColumnPositionSentence(planColumns, i)))
+			current = append(current[:position], current[position+1:]...)
+			current = append(current[:i], append([]ColumnResource{planCol}, current[i:]...)...)
+			continue
+		}
+
+		if remaining != planCol {
+			actions = append(actions, fmt.Sprintf("MODIFY COLUMN %s", buildColumnDefinition(planCol)))
+		}
+		current[position] = planCol
+	}
+
+	return actions
+}
+
+// buildAlterIndexesActions computes the ALTER TABLE actions that turn the state indexes into the plan ones.
+// Indexes can't be modified, so changed indexes are dropped and added again. The drop actions are returned apart
+// from the add ones, as they must run before the columns the dropped indexes reference are altered.
+func buildAlterIndexesActions(stateIndexes []IndexResource, planIndexes []IndexResource) (dropActions []string, addActions []string) {
+	dropActions, addActions = make([]string, 0), make([]string, 0)
+
+	planByName := make(map[string]IndexResource)
+	for _, index := range planIndexes {
+		planByName[index.Name] = index
+	}
+	stateByName := make(map[string]IndexResource)
+	for _, index := range stateIndexes {
+		stateByName[index.Name] = index
+		if planIndex, ok := planByName[index.Name]; !ok || !indexesEquivalent(index, planIndex) {
+			dropActions = append(dropActions, fmt.Sprintf("DROP INDEX %s", common.QuoteIdentifier(index.Name)))
+		}
+	}
+
+	for _, index := range planIndexes {
+		if stateIndex, ok := stateByName[index.Name]; ok && indexesEquivalent(stateIndex, index) {
+			continue
+		}
+		addActions = append(addActions, fmt.Sprintf("ADD %s", buildIndexDefinition(index)))
+		if index.Materialize {
+			addActions = append(addActions, fmt.Sprintf("MATERIALIZE INDEX %s", common.QuoteIdentifier(index.Name)))
+		}
+	}
+	return dropActions, addActions
+}
+
+// buildAlterProjectionsActions computes the ALTER TABLE actions that turn the state projections into the plan ones.
+// Projections can't be modified, so changed projections are dropped and added again. As for indexes, the drop
+// actions are returned apart from the add ones.
+func buildAlterProjectionsActions(stateProjections []ProjectionResource, planProjections []ProjectionResource) (dropActions []string, addActions []string) {
+	dropActions, addActions = make([]string, 0), make([]string, 0)
+
+	planByName := make(map[string]ProjectionResource)
+	for _, projection := range planProjections {
+		planByName[projection.Name] = projection
+	}
+	stateByName := make(map[string]ProjectionResource)
+	for _, projection := range stateProjections {
+		stateByName[projection.Name] = projection
+		if planProjection, ok := planByName[projection.Name]; !ok || !projectionsEquivalent(projection, planProjection) {
+			dropActions = append(dropActions, fmt.Sprintf("DROP PROJECTION %s", common.QuoteIdentifier(projection.Name)))
+		}
+	}
+
+	for _, projection := range planProjections {
+		if stateProjection, ok := stateByName[projection.Name]; ok && projectionsEquivalent(stateProjection, projection) {
+			continue
+		}
+		addActions = append(addActions, fmt.Sprintf("ADD %s", buildProjectionDefinition(projection)))
+		if projection.Materialize {
+			addActions = append(addActions, fmt.Sprintf("MATERIALIZE PROJECTION %s", common.QuoteIdentifier(projection.Name)))
+		}
+	}
+	return dropActions, addActions
+}
+
+func buildAlterTableSentence(resource TableResource, action string) string {
+	return fmt.Sprintf(
+		"ALTER TABLE %v %v %v",
+		common.QualifiedName(resource.Database, resource.Name),
+		common.GetClusterStatement(resource.Cluster),
+		action,
 	)
 }
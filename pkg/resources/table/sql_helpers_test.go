@@ -0,0 +1,143 @@
+package resourcetable
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+)
+
+func column(name string, columnType string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":               name,
+		"type":               columnType,
+		"default_kind":       "",
+		"default_expression": "",
+		"codec":              "",
+		"ttl":                "",
+		"comment":            "",
+	}
+}
+
+func TestBuildCreateOnClusterSentence(t *testing.T) {
+	event := column("event_date", "Date")
+	event["ttl"] = "event_date + INTERVAL 1 MONTH"
+	payload := column("payload", "String")
+	payload["default_kind"] = "DEFAULT"
+	payload["default_expression"] = "''"
+	payload["codec"] = "ZSTD(3)"
+	payload["comment"] = "raw 'json' payload"
+
+	tests := map[string]TableResource{
+		"create_table_minimal": {
+			Database: "db",
+			Name:     "events",
+			Engine:   "MergeTree",
+			OrderBy:  []string{"id"},
+			Columns:  []interface{}{column("id", "UInt64")},
+		},
+		"create_table_full": {
+			Database:     "db",
+			Name:         "events",
+			Cluster:      "cluster",
+			Engine:       "ReplicatedReplacingMergeTree",
+			EngineParams: []string{"'/clickhouse/{shard}/events'", "'{replica}'", "version"},
+			Comment:      "{\"comment\":\"events\"}",
+			OrderBy:      []string{"id", "cityHash64(id)"},
+			PrimaryKey:   []string{"id"},
+			SampleBy:     "cityHash64(id)",
+			PartitionBy:  []PartitionByResource{{By: "event_date", PartitionFunction: "toYYYYMM"}, {By: "id % 4"}},
+			Columns:      []interface{}{column("id", "UInt64"), column("version", "UInt32"), event, payload},
+			TTL: []TTLResource{
+				{Expression: "event_date + INTERVAL 1 WEEK", Action: "TO VOLUME", Target: "cold"},
+				{Expression: "event_date + INTERVAL 1 YEAR", Where: "version = 0"},
+			},
+			Settings:    map[string]string{"storage_policy": "tiered", "index_granularity": "8192"},
+			Indexes:     []IndexResource{{Name: "payload_idx", Expression: "payload", Type: "tokenbf_v1(512, 3, 0)", Granularity: 4}},
+			Projections: []ProjectionResource{{Name: "by_version", Query: "SELECT * ORDER BY version"}},
+		},
+		"create_table_distributed": {
+			Database:     "db",
+			Name:         "events_all",
+			Cluster:      "'{cluster}'",
+			Engine:       "Distributed",
+			EngineParams: []string{"'{cluster}'", "'db'", "'events'", "rand()"},
+			Columns:      []interface{}{column("id", "UInt64")},
+		},
+	}
+
+	for name, table := range tests {
+		t.Run(name, func(t *testing.T) {
+			golden.Assert(t, name, buildCreateOnClusterSentence(table))
+		})
+	}
+}
+
+func TestBuildAlterTableSentences(t *testing.T) {
+	table := TableResource{Database: "db", Name: "events", Cluster: "cluster"}
+	state := []ColumnResource{
+		{Name: "id", Type: "UInt64"},
+		{Name: "name", Type: "String", Comment: "user name"},
+		{Name: "legacy", Type: "String", Codec: "ZSTD"},
+	}
+	plan := []ColumnResource{
+		{Name: "id", Type: "UInt64"},
+		{Name: "created_at", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()"},
+		{Name: "name", Type: "LowCardinality(String)"},
+	}
+	stateIndexes := []IndexResource{{Name: "name_idx", Expression: "name", Type: "bloom_filter", Granularity: 1}}
+	planIndexes := []IndexResource{{Name: "name_idx", Expression: "lower(name)", Type: "bloom_filter", Granularity: 2, Materialize: true}}
+	stateProjections := []ProjectionResource{{Name: "by_name", Query: "SELECT * ORDER BY name"}}
+	planProjections := []ProjectionResource{{Name: "by_date", Query: "SELECT * ORDER BY created_at", Materialize: true}}
+
+	dropIndexes, addIndexes := buildAlterIndexesActions(stateIndexes, planIndexes)
+	dropProjections, addProjections := buildAlterProjectionsActions(stateProjections, planProjections)
+
+	var actions []string
+	actions = append(actions, dropIndexes...)
+	actions = append(actions, dropProjections...)
+	actions = append(actions, buildAlterColumnsActions(state, plan)...)
+	actions = append(actions, "MODIFY TTL "+buildTTLClause([]TTLResource{{Expression: "created_at + INTERVAL 1 DAY", Action: "GROUP BY", GroupBy: "id", Set: "name = any(name)"}}))
+	actions = append(actions, "MODIFY SETTING "+strings.Join(buildSettingsItems(map[string]string{"merge_with_ttl_timeout": "3600", "storage_policy": "hot"}), ", "))
+	actions = append(actions, addIndexes...)
+	actions = append(actions, addProjections...)
+
+	var statements []string
+	for _, action := range actions {
+		statements = append(statements, buildAlterTableSentence(table, action))
+	}
+	golden.Assert(t, "alter_table", strings.Join(statements, "\n"))
+}
+
+func TestBuildAlterColumnsActionsRename(t *testing.T) {
+	state := []ColumnResource{{Name: "id", Type: "UInt64"}, {Name: "name", Type: "String", Comment: "user name"}}
+	tests := []struct {
+		name     string
+		plan     []ColumnResource
+		expected []string
+	}{
+		{
+			name:     "same definition",
+			plan:     []ColumnResource{{Name: "id", Type: "UInt64"}, {Name: "login", Type: "String", Comment: "user name"}},
+			expected: []string{"RENAME COLUMN `name` TO `login`"},
+		},
+		{
+			name:     "different type",
+			plan:     []ColumnResource{{Name: "id", Type: "UInt64"}, {Name: "login", Type: "UInt32", Comment: "user name"}},
+			expected: []string{"DROP COLUMN `name`", "ADD COLUMN `login` UInt32 COMMENT 'user name' AFTER `id`"},
+		},
+		{
+			name:     "different comment",
+			plan:     []ColumnResource{{Name: "id", Type: "UInt64"}, {Name: "login", Type: "String"}},
+			expected: []string{"DROP COLUMN `name`", "ADD COLUMN `login` String AFTER `id`"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actions := buildAlterColumnsActions(state, test.plan); !reflect.DeepEqual(actions, test.expected) {
+				t.Errorf("buildAlterColumnsActions() = %q, expected %q", actions, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package resourcetable
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+var partitionFunctionRegex = regexp.MustCompile(`^(toYYYYMM|toYYYYMMDD|toYYYYMMDDhhmmss)\((\w+)\)$`)
+
+// splitTopLevel splits an expression list by commas that are not nested inside parentheses, brackets or quotes.
+func splitTopLevel(expression string) []string {
+	items := make([]string, 0)
+	depth := 0
+	var quote rune
+	escaped := false
+	start := 0
+
+	for i, c := range expression {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(expression[start:i]))
+			start = i + 1
+		}
+	}
+
+	if last := strings.TrimSpace(expression[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items
+}
+
+// closingParenthesis returns the index of the parenthesis closing the one opened at position open, or -1.
+func closingParenthesis(expression string, open int) int {
+	depth := 0
+	var quote rune
+	escaped := false
+
+	for i, c := range expression[open:] {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return open + i
+			}
+		}
+	}
+	return -1
+}
+
+// unwrapParentheses removes the parentheses surrounding a whole expression, e.g. "(a, b)" becomes "a, b".
+func unwrapParentheses(expression string) string {
+	expression = strings.TrimSpace(expression)
+	for strings.HasPrefix(expression, "(") && closingParenthesis(expression, 0) == len(expression)-1 {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+	return expression
+}
+
+// parseEngineParams extracts the engine arguments from system.tables engine_full,
+// e.g. "ReplacingMergeTree(eventTime) ORDER BY key" returns ["eventTime"].
+func parseEngineParams(engine string, engineFull string) []string {
+	if !strings.HasPrefix(engineFull, engine+"(") {
+		return make([]string, 0)
+	}
+	open := len(engine)
+	closing := closingParenthesis(engineFull, open)
+	if closing == -1 {
+		return make([]string, 0)
+	}
+	return splitTopLevel(engineFull[open+1 : closing])
+}
+
+// parseSortingKey converts system.tables sorting_key into the order_by list.
+func parseSortingKey(sortingKey string) []string {
+	return splitTopLevel(unwrapParentheses(sortingKey))
+}
+
+// parsePartitionKey converts system.tables partition_key into partition_by items.
+func parsePartitionKey(partitionKey string) []PartitionByResource {
+	partitionBy := make([]PartitionByResource, 0)
+	for _, item := range splitTopLevel(unwrapParentheses(partitionKey)) {
+		matches := partitionFunctionRegex.FindStringSubmatch(item)
+		if matches != nil {
+			partitionBy = append(partitionBy, PartitionByResource{By: matches[2], PartitionFunction: matches[1]})
+		} else {
+			partitionBy = append(partitionBy, PartitionByResource{By: item})
+		}
+	}
+	return partitionBy
+}
+
+// expressionKeywords are the words allowed in key expressions that aren't column references.
+var expressionKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "IS": true, "NULL": true, "LIKE": true, "ILIKE": true,
+	"BETWEEN": true, "CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "TRUE": true,
+	"FALSE": true, "INTERVAL": true, "SECOND": true, "MINUTE": true, "HOUR": true, "DAY": true, "WEEK": true,
+	"MONTH": true, "QUARTER": true, "YEAR": true, "ASC": true, "DESC": true, "AS": true,
+}
+
+// referencedIdentifiers returns the column identifiers referenced by an expression, skipping literals,
+// function names and keywords, e.g. "(cityHash64(user_id), ts)" references user_id and ts.
+func referencedIdentifiers(expression string) []string {
+	identifiers := make([]string, 0)
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\'' || c == '"':
+			for i++; i < len(runes) && runes[i] != c; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+		case c == '`':
+			start := i + 1
+			for i++; i < len(runes) && runes[i] != '`'; i++ {
+			}
+			identifiers = append(identifiers, string(runes[start:i]))
+		case unicode.IsDigit(c):
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_' || runes[i+1] == '.') {
+				i++
+			}
+			word := string(runes[start : i+1])
+			next := i + 1
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			if (next < len(runes) && runes[next] == '(') || expressionKeywords[strings.ToUpper(word)] {
+				continue
+			}
+			identifiers = append(identifiers, word)
+		}
+	}
+	return identifiers
+}
+
+// normalizeExpression returns the canonical form of an expression so that expressions formatted by Clickhouse can be
+// compared with the ones written by the user, e.g. "a+b" and "a + b", see common.NormalizeQuery.
+func normalizeExpression(expression string) string {
+	return common.NormalizeQuery(expression, "")
+}
+
+// unwrapCodec removes the CODEC(...) wrapper reported by system.columns compression_codec.
+func unwrapCodec(codec string) string {
+	codec = strings.TrimSpace(codec)
+	if strings.HasPrefix(codec, "CODEC(") && closingParenthesis(codec, len("CODEC")) == len(codec)-1 {
+		return strings.TrimSpace(codec[len("CODEC(") : len(codec)-1])
+	}
+	return codec
+}
+
+// codecsEquivalent compares two codec lists, codecs without params match the same codec with the params
+// filled in by Clickhouse, e.g. "Delta, ZSTD" is equivalent to "Delta(8), ZSTD(1)".
+func codecsEquivalent(a string, b string) bool {
+	codecsA := splitTopLevel(unwrapCodec(a))
+	codecsB := splitTopLevel(unwrapCodec(b))
+	if len(codecsA) != len(codecsB) {
+		return false
+	}
+	for i := range codecsA {
+		codecA, codecB := normalizeExpression(codecsA[i]), normalizeExpression(codecsB[i])
+		if codecA == codecB {
+			continue
+		}
+		nameA, nameB := strings.SplitN(codecA, "(", 2)[0], strings.SplitN(codecB, "(", 2)[0]
+		if nameA != nameB || (strings.Contains(codecA, "(") && strings.Contains(codecB, "(")) {
+			return false
+		}
+	}
+	return true
+}
+
+// findTopLevelKeyword returns the index of the first occurrence of keyword (surrounded by spaces)
+// that isn't nested inside parentheses or quotes, or -1.
+func findTopLevelKeyword(expression string, keyword string) int {
+	depth := 0
+	var quote rune
+	escaped := false
+	keyword = " " + keyword + " "
+	padded := expression + " "
+
+	for i, c := range expression {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ' ' && depth == 0 && strings.HasPrefix(padded[i:], keyword):
+			return i
+		}
+	}
+	return -1
+}
+
+// extractClause returns the clause started by keyword in a storage definition like engine_full,
+// ending where the first of the following clauses starts.
+func extractClause(definition string, keyword string, nextKeywords ...string) string {
+	start := findTopLevelKeyword(definition, keyword)
+	if start == -1 {
+		return ""
+	}
+	clause := definition[start+len(keyword)+2:]
+	for _, nextKeyword := range nextKeywords {
+		if end := findTopLevelKeyword(clause, nextKeyword); end != -1 {
+			clause = clause[:end]
+		}
+	}
+	return strings.TrimSpace(clause)
+}
+
+// parseTTLClause converts the TTL clause of engine_full into ttl items.
+func parseTTLClause(clause string) []TTLResource {
+	ttls := make([]TTLResource, 0)
+	for _, item := range splitTopLevel(clause) {
+		ttl := TTLResource{}
+		if index := findTopLevelKeyword(item, "SET"); index != -1 {
+			ttl.Set = strings.TrimSpace(item[index+len(" SET "):])
+			item = item[:index]
+		}
+		if index := findTopLevelKeyword(item, "GROUP BY"); index != -1 {
+			ttl.Action = "GROUP BY"
+			ttl.GroupBy = strings.TrimSpace(item[index+len(" GROUP BY "):])
+			item = item[:index]
+		}
+		if index := findTopLevelKeyword(item, "WHERE"); index != -1 {
+			ttl.Where = strings.TrimSpace(item[index+len(" WHERE "):])
+			item = item[:index]
+		}
+		for _, action := range []string{"TO DISK", "TO VOLUME"} {
+			if index := findTopLevelKeyword(item, action); index != -1 {
+				ttl.Action = action
+				ttl.Target = strings.Trim(strings.TrimSpace(item[index+len(action)+2:]), "'")
+				item = item[:index]
+			}
+		}
+		item = strings.TrimSpace(item)
+		if strings.HasSuffix(item, " DELETE") {
+			item = strings.TrimSpace(strings.TrimSuffix(item, " DELETE"))
+		}
+		if ttl.Action == "" {
+			ttl.Action = "DELETE"
+		}
+		ttl.Expression = item
+		ttls = append(ttls, ttl)
+	}
+	return ttls
+}
+
+// parseSettingsClause converts the SETTINGS clause of engine_full into a settings map, string values are unquoted.
+func parseSettingsClause(clause string) map[string]string {
+	settings := make(map[string]string)
+	for _, item := range splitTopLevel(clause) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		if isStringLiteral(value) {
+			value = strings.Replace(value[1:len(value)-1], "\\'", "'", -1)
+		}
+		settings[strings.TrimSpace(parts[0])] = value
+	}
+	return settings
+}
+
+// createTableElements returns the elements declared between the parentheses of a create_table_query:
+// columns, indexes, projections and constraints.
+func createTableElements(createTableQuery string) []string {
+	open := strings.Index(createTableQuery, "(")
+	if open == -1 {
+		return make([]string, 0)
+	}
+	closing := closingParenthesis(createTableQuery, open)
+	if closing == -1 {
+		return make([]string, 0)
+	}
+	return splitTopLevel(createTableQuery[open+1 : closing])
+}
+
+// createTableStorage returns the storage definition following the elements of a create_table_query,
+// e.g. "ENGINE = MergeTree ORDER BY key TTL ... SETTINGS ... COMMENT ...".
+func createTableStorage(createTableQuery string) string {
+	open := strings.Index(createTableQuery, "(")
+	if open == -1 {
+		return ""
+	}
+	closing := closingParenthesis(createTableQuery, open)
+	if closing == -1 {
+		return ""
+	}
+	return " " + strings.TrimSpace(createTableQuery[closing+1:])
+}
+
+// parseProjections extracts the projections from system.tables create_table_query, as they are only listed
+// in system.projection_parts once they hold data, e.g. "PROJECTION p (SELECT * ORDER BY b)".
+func parseProjections(createTableQuery string) []ProjectionResource {
+	projections := make([]ProjectionResource, 0)
+	for _, item := range createTableElements(createTableQuery) {
+		if !strings.HasPrefix(item, "PROJECTION ") {
+			continue
+		}
+		definition := strings.TrimSpace(strings.TrimPrefix(item, "PROJECTION "))
+		nameEnd := strings.IndexAny(definition, " \t\n(")
+		if nameEnd == -1 {
+			continue
+		}
+		projections = append(projections, ProjectionResource{
+			Name:  strings.Trim(definition[:nameEnd], "`"),
+			Query: strings.Join(strings.Fields(unwrapParentheses(definition[nameEnd:])), " "),
+		})
+	}
+	return projections
+}
+
+// parseColumnTTLs extracts the column TTLs from system.tables create_table_query, as system.columns doesn't
+// expose them, e.g. "`ts` DateTime TTL ts + toIntervalDay(1)" returns {"ts": "ts + toIntervalDay(1)"}.
+func parseColumnTTLs(createTableQuery string) map[string]string {
+	ttls := make(map[string]string)
+	for _, item := range createTableElements(createTableQuery) {
+		name := item
+		if strings.HasPrefix(item, "`") {
+			if end := strings.Index(item[1:], "`"); end != -1 {
+				name = item[1 : end+1]
+			}
+		} else if end := strings.IndexAny(item, " \t\n"); end != -1 {
+			name = item[:end]
+		}
+		if ttl := extractClause(item, "TTL", "SETTINGS"); ttl != "" {
+			ttls[name] = ttl
+		}
+	}
+	return ttls
+}
+
+// indexesEquivalent compares two data skipping indexes ignoring the formatting of their expressions.
+func indexesEquivalent(a IndexResource, b IndexResource) bool {
+	return a.Name == b.Name &&
+		normalizeExpression(a.Expression) == normalizeExpression(b.Expression) &&
+		normalizeExpression(a.Type) == normalizeExpression(b.Type) &&
+		a.Granularity == b.Granularity
+}
+
+// projectionsEquivalent compares two projections ignoring the formatting of their queries.
+func projectionsEquivalent(a ProjectionResource, b ProjectionResource) bool {
+	return a.Name == b.Name && normalizeExpression(a.Query) == normalizeExpression(b.Query)
+}
@@ -0,0 +1,153 @@
+package resourcetable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEngineParams(t *testing.T) {
+	tests := []struct {
+		engine     string
+		engineFull string
+		expected   []string
+	}{
+		{"ReplacingMergeTree", "ReplacingMergeTree(eventTime) ORDER BY key SETTINGS index_granularity = 8192", []string{"eventTime"}},
+		{"MergeTree", "MergeTree ORDER BY key", []string{}},
+		{"Distributed", "Distributed('cluster', 'db', 'table', rand())", []string{"'cluster'", "'db'", "'table'", "rand()"}},
+		{"ReplicatedMergeTree", "ReplicatedMergeTree('/clickhouse/{shard}, x', '{replica}') ORDER BY (a, b)", []string{"'/clickhouse/{shard}, x'", "'{replica}'"}},
+		{"SummingMergeTree", "SummingMergeTree((a, b)) ORDER BY a", []string{"(a, b)"}},
+	}
+
+	for _, test := range tests {
+		params := parseEngineParams(test.engine, test.engineFull)
+		if !reflect.DeepEqual(params, test.expected) {
+			t.Errorf("parseEngineParams(%q) = %q, expected %q", test.engineFull, params, test.expected)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	if orderBy := parseSortingKey("event_date, cityHash64(user_id, 'a,b')"); !reflect.DeepEqual(orderBy, []string{"event_date", "cityHash64(user_id, 'a,b')"}) {
+		t.Errorf("unexpected sorting key %q", orderBy)
+	}
+	if orderBy := parseSortingKey(""); len(orderBy) != 0 {
+		t.Errorf("unexpected sorting key %q", orderBy)
+	}
+
+	partitionBy := parsePartitionKey("(event_type, toYYYYMM(event_date))")
+	expected := []PartitionByResource{{By: "event_type"}, {By: "event_date", PartitionFunction: "toYYYYMM"}}
+	if !reflect.DeepEqual(partitionBy, expected) {
+		t.Errorf("unexpected partition key %v", partitionBy)
+	}
+}
+
+func TestCodecsEquivalent(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"Delta, ZSTD", "CODEC(Delta(8), ZSTD(1))", true},
+		{"ZSTD(3)", "CODEC(ZSTD(3))", true},
+		{"ZSTD(3)", "CODEC(ZSTD(1))", false},
+		{"Delta, ZSTD", "CODEC(ZSTD(1))", false},
+		{"LZ4", "CODEC(ZSTD(1))", false},
+		{"", "", true},
+	}
+
+	for _, test := range tests {
+		if codecsEquivalent(test.a, test.b) != test.expected {
+			t.Errorf("codecsEquivalent(%q, %q) expected %v", test.a, test.b, test.expected)
+		}
+	}
+}
+
+func TestNormalizeExpression(t *testing.T) {
+	if normalizeExpression("a +  b * toDate( ts )") != normalizeExpression("a+b*toDate(ts)") {
+		t.Errorf("expressions differing only in whitespaces should be equal")
+	}
+	if normalizeExpression("concat(a, ' ')") == normalizeExpression("concat(a, '')") {
+		t.Errorf("whitespaces inside string literals must be kept")
+	}
+	if normalizeExpression("a b") == normalizeExpression("ab") {
+		t.Errorf("whitespaces separating identifiers must be kept")
+	}
+	if normalizeExpression("ts + INTERVAL 1 DAY") != normalizeExpression("ts + toIntervalDay(1)") {
+		t.Errorf("INTERVAL literals should be equal to their toInterval function")
+	}
+}
+
+func TestParseTTLAndSettingsClauses(t *testing.T) {
+	createTableQuery := "CREATE TABLE db.events (`key` Int64, `eventTime` DateTime TTL eventTime + toIntervalDay(1), `value` UInt64) " +
+		"ENGINE = MergeTree ORDER BY (key, eventTime) TTL eventTime + toIntervalMonth(1) TO VOLUME 'cold', " +
+		"eventTime + toIntervalYear(1) WHERE key = 1, eventTime + toIntervalDay(7) GROUP BY key SET value = sum(value) " +
+		"SETTINGS index_granularity = 8192, storage_policy = 'tiered' COMMENT 'events'"
+	storage := createTableStorage(createTableQuery)
+
+	ttls := parseTTLClause(extractClause(storage, "TTL", "SETTINGS", "COMMENT"))
+	expected := []TTLResource{
+		{Expression: "eventTime + toIntervalMonth(1)", Action: "TO VOLUME", Target: "cold"},
+		{Expression: "eventTime + toIntervalYear(1)", Action: "DELETE", Where: "key = 1"},
+		{Expression: "eventTime + toIntervalDay(7)", Action: "GROUP BY", GroupBy: "key", Set: "value = sum(value)"},
+	}
+	if !reflect.DeepEqual(ttls, expected) {
+		t.Errorf("unexpected ttl %+v", ttls)
+	}
+
+	configured := []TTLResource{
+		{Expression: "eventTime + INTERVAL 1 MONTH", Action: "TO VOLUME", Target: "cold"},
+		{Expression: "eventTime + INTERVAL 1 YEAR", Action: "DELETE", Where: "key = 1"},
+		{Expression: "eventTime + INTERVAL 7 DAY", Action: "GROUP BY", GroupBy: "key", Set: "value = sum(value)"},
+	}
+	if normalizeExpression(buildTTLClause(configured)) != normalizeExpression(buildTTLClause(ttls)) {
+		t.Errorf("configured ttl %q should be equivalent to %q", buildTTLClause(configured), buildTTLClause(ttls))
+	}
+
+	settings := parseSettingsClause(extractClause(storage, "SETTINGS", "COMMENT"))
+	if !reflect.DeepEqual(settings, map[string]string{"index_granularity": "8192", "storage_policy": "tiered"}) {
+		t.Errorf("unexpected settings %v", settings)
+	}
+}
+
+func TestParseProjections(t *testing.T) {
+	createTableQuery := "CREATE TABLE db.events\n(\n    `key` Int64,\n    `note` String DEFAULT 'PROJECTION x (SELECT 1)',\n    INDEX idx_note note TYPE bloom_filter GRANULARITY 4,\n    PROJECTION by_key\n    (\n        SELECT *\n        ORDER BY key\n    )\n)\nENGINE = MergeTree\nORDER BY key"
+
+	expected := []ProjectionResource{{Name: "by_key", Query: "SELECT * ORDER BY key"}}
+	if projections := parseProjections(createTableQuery); !reflect.DeepEqual(projections, expected) {
+		t.Errorf("unexpected projections %v", projections)
+	}
+	if projections := parseProjections(""); len(projections) != 0 {
+		t.Errorf("unexpected projections %v", projections)
+	}
+}
+
+func TestParseColumnTTLs(t *testing.T) {
+	createTableQuery := "CREATE TABLE db.events\n(\n    `ts` DateTime,\n    `note` String DEFAULT 'TTL ts' COMMENT 'x' CODEC(ZSTD(1)) TTL ts + toIntervalDay(1),\n    `odd name` UInt8 TTL ts + toIntervalMonth(1) SETTINGS (max_compress_block_size = 1024),\n    INDEX idx_note note TYPE bloom_filter GRANULARITY 4\n)\nENGINE = MergeTree\nORDER BY ts\nTTL ts + toIntervalYear(1)"
+
+	expected := map[string]string{"note": "ts + toIntervalDay(1)", "odd name": "ts + toIntervalMonth(1)"}
+	if ttls := parseColumnTTLs(createTableQuery); !reflect.DeepEqual(ttls, expected) {
+		t.Errorf("unexpected column TTLs %v", ttls)
+	}
+	if ttls := parseColumnTTLs(""); len(ttls) != 0 {
+		t.Errorf("unexpected column TTLs %v", ttls)
+	}
+}
+
+func TestReferencedIdentifiers(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   []string
+	}{
+		{"(cityHash64(user_id), ts)", []string{"user_id", "ts"}},
+		{"toMonday(ts)", []string{"ts"}},
+		{"tenant_id % 16", []string{"tenant_id"}},
+		{"if(status = 'a b', `odd name`, point.x)", []string{"status", "odd name", "point.x"}},
+		{"ts + INTERVAL 1 DAY", []string{"ts"}},
+		{"tuple()", []string{}},
+	}
+
+	for _, test := range tests {
+		if identifiers := referencedIdentifiers(test.expression); !reflect.DeepEqual(identifiers, test.expected) {
+			t.Errorf("referencedIdentifiers(%q) = %q, expected %q", test.expression, identifiers, test.expected)
+		}
+	}
+}
@@ -0,0 +1,672 @@
+package resourcetable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ChType is a parsed ClickHouse data type expression, e.g. Map(String, Array(Nullable(UInt64))).
+type ChType struct {
+	Name   string
+	Params []ChTypeParam
+	// Position of the type name in the parsed expression (1-based)
+	Position int
+}
+
+// ChTypeParam is a type parameter: either a nested type (optionally named, as in Tuple or Nested),
+// a literal (as in FixedString(16) or DateTime('UTC')), or an enum entry ('a' = 1).
+type ChTypeParam struct {
+	Name    string
+	Type    *ChType
+	Literal string
+	Value   string
+}
+
+type typeKind int
+
+const (
+	kindSimple typeKind = iota
+	kindFixedString
+	kindDecimal
+	kindDecimalScale
+	kindDateTime
+	kindDateTime64
+	kindEnum
+	kindWrapper
+	kindMap
+	kindTuple
+	kindNested
+	kindVariant
+	kindAggregateFunction
+	kindFreeForm
+)
+
+type typeSpec struct {
+	kind typeKind
+	// Type can be wrapped by Nullable
+	nullable bool
+	// Type can be wrapped by LowCardinality
+	lowCardinality bool
+	// Type can be used as Map key
+	mapKey bool
+}
+
+var integerSpec = typeSpec{kind: kindSimple, nullable: true, lowCardinality: true, mapKey: true}
+var floatSpec = typeSpec{kind: kindSimple, nullable: true, lowCardinality: true}
+var geoSpec = typeSpec{kind: kindSimple}
+
+var typeSpecs = map[string]typeSpec{
+	"UInt8":                   integerSpec,
+	"UInt16":                  integerSpec,
+	"UInt32":                  integerSpec,
+	"UInt64":                  integerSpec,
+	"UInt128":                 integerSpec,
+	"UInt256":                 integerSpec,
+	"Int8":                    integerSpec,
+	"Int16":                   integerSpec,
+	"Int32":                   integerSpec,
+	"Int64":                   integerSpec,
+	"Int128":                  integerSpec,
+	"Int256":                  integerSpec,
+	"Float32":                 floatSpec,
+	"Float64":                 floatSpec,
+	"BFloat16":                floatSpec,
+	"Bool":                    {kind: kindSimple, nullable: true, mapKey: true},
+	"String":                  {kind: kindSimple, nullable: true, lowCardinality: true, mapKey: true},
+	"UUID":                    {kind: kindSimple, nullable: true, mapKey: true},
+	"Date":                    {kind: kindSimple, nullable: true, lowCardinality: true, mapKey: true},
+	"Date32":                  {kind: kindSimple, nullable: true, lowCardinality: true, mapKey: true},
+	"IPv4":                    {kind: kindSimple, nullable: true, mapKey: true},
+	"IPv6":                    {kind: kindSimple, nullable: true, mapKey: true},
+	"Nothing":                 {kind: kindSimple, nullable: true},
+	"Dynamic":                 {kind: kindFreeForm},
+	"Point":                   geoSpec,
+	"Ring":                    geoSpec,
+	"Polygon":                 geoSpec,
+	"MultiPolygon":            geoSpec,
+	"LineString":              geoSpec,
+	"MultiLineString":         geoSpec,
+	"JSON":                    {kind: kindFreeForm},
+	"Object":                  {kind: kindFreeForm},
+	"FixedString":             {kind: kindFixedString, nullable: true, lowCardinality: true, mapKey: true},
+	"Decimal":                 {kind: kindDecimal, nullable: true},
+	"Decimal32":               {kind: kindDecimalScale, nullable: true},
+	"Decimal64":               {kind: kindDecimalScale, nullable: true},
+	"Decimal128":              {kind: kindDecimalScale, nullable: true},
+	"Decimal256":              {kind: kindDecimalScale, nullable: true},
+	"DateTime":                {kind: kindDateTime, nullable: true, lowCardinality: true, mapKey: true},
+	"DateTime64":              {kind: kindDateTime64, nullable: true, mapKey: true},
+	"Enum":                    {kind: kindEnum, nullable: true, mapKey: true},
+	"Enum8":                   {kind: kindEnum, nullable: true, mapKey: true},
+	"Enum16":                  {kind: kindEnum, nullable: true, mapKey: true},
+	"Nullable":                {kind: kindWrapper},
+	"LowCardinality":          {kind: kindWrapper, mapKey: true},
+	"Array":                   {kind: kindWrapper},
+	"Map":                     {kind: kindMap},
+	"Tuple":                   {kind: kindTuple},
+	"Nested":                  {kind: kindNested},
+	"Variant":                 {kind: kindVariant},
+	"AggregateFunction":       {kind: kindAggregateFunction},
+	"SimpleAggregateFunction": {kind: kindAggregateFunction},
+}
+
+// typeAliases maps the case insensitive SQL compatibility aliases accepted by Clickhouse to the type they stand for.
+var typeAliases = map[string]string{
+	"TINYINT":  "Int8",
+	"SMALLINT": "Int16",
+	"INT":      "Int32",
+	"INTEGER":  "Int32",
+	"BIGINT":   "Int64",
+	"FLOAT":    "Float32",
+	"REAL":     "Float32",
+	"DOUBLE":   "Float64",
+	"BOOLEAN":  "Bool",
+	"CHAR":     "String",
+	"VARCHAR":  "String",
+	"TEXT":     "String",
+	"BLOB":     "String",
+}
+
+// decimalPrecisions holds the precision of the DecimalN(S) shortcuts, reported by Clickhouse as Decimal(P, S).
+var decimalPrecisions = map[string]string{
+	"Decimal32":  "9",
+	"Decimal64":  "18",
+	"Decimal128": "38",
+	"Decimal256": "76",
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenNumber
+	tokenString
+	tokenPunctuation
+)
+
+type typeToken struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// TypeSyntaxError reports the position (1-based) in the type expression where parsing failed.
+type TypeSyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *TypeSyntaxError) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Position, e.Message)
+}
+
+func tokenizeType(input string) ([]typeToken, error) {
+	var tokens []typeToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, typeToken{kind: tokenPunctuation, text: string(c), position: i + 1})
+			i++
+		case c == '\'':
+			start := i
+			i++
+			for ; i < len(runes); i++ {
+				if runes[i] == '\\' {
+					i++
+				} else if runes[i] == '\'' {
+					// A doubled quote is an escaped quote
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			if i >= len(runes) {
+				return nil, &TypeSyntaxError{Position: start + 1, Message: "unterminated string literal"}
+			}
+			i++
+			tokens = append(tokens, typeToken{kind: tokenString, text: string(runes[start:i]), position: start + 1})
+		case unicode.IsDigit(c) || c == '-' || c == '+':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			if i == start+1 && !unicode.IsDigit(c) {
+				return nil, &TypeSyntaxError{Position: start + 1, Message: fmt.Sprintf("unexpected character %q", c)}
+			}
+			tokens = append(tokens, typeToken{kind: tokenNumber, text: string(runes[start:i]), position: start + 1})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, typeToken{kind: tokenIdentifier, text: string(runes[start:i]), position: start + 1})
+		default:
+			return nil, &TypeSyntaxError{Position: i + 1, Message: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return append(tokens, typeToken{kind: tokenEOF, position: len(runes) + 1}), nil
+}
+
+type typeParser struct {
+	tokens  []typeToken
+	current int
+}
+
+// ParseType parses a ClickHouse type expression and checks the nesting rules between types,
+// e.g. Nullable can't wrap composite types and LowCardinality only wraps strings, dates and numbers.
+func ParseType(input string) (*ChType, error) {
+	tokens, err := tokenizeType(input)
+	if err != nil {
+		return nil, err
+	}
+	parser := typeParser{tokens: tokens}
+
+	chType, err := parser.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tokenEOF {
+		return nil, parser.unexpected(token, "end of type")
+	}
+	if err := checkTypeNesting(chType, nil); err != nil {
+		return nil, err
+	}
+	return chType, nil
+}
+
+func (p *typeParser) peek() typeToken {
+	return p.tokens[p.current]
+}
+
+func (p *typeParser) next() typeToken {
+	token := p.tokens[p.current]
+	if token.kind != tokenEOF {
+		p.current++
+	}
+	return token
+}
+
+func (p *typeParser) unexpected(token typeToken, expected string) error {
+	found := fmt.Sprintf("%q", token.text)
+	if token.kind == tokenEOF {
+		found = "end of type"
+	}
+	return &TypeSyntaxError{Position: token.position, Message: fmt.Sprintf("expected %s but found %s", expected, found)}
+}
+
+func (p *typeParser) expect(punctuation string) error {
+	token := p.next()
+	if token.kind != tokenPunctuation || token.text != punctuation {
+		return p.unexpected(token, fmt.Sprintf("%q", punctuation))
+	}
+	return nil
+}
+
+func (p *typeParser) accept(punctuation string) bool {
+	token := p.peek()
+	if token.kind == tokenPunctuation && token.text == punctuation {
+		p.current++
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) expectInteger(min int, max int) (int, typeToken, error) {
+	token := p.next()
+	if token.kind != tokenNumber {
+		return 0, token, p.unexpected(token, "an integer")
+	}
+	value, err := strconv.Atoi(token.text)
+	if err != nil || value < min || value > max {
+		return 0, token, &TypeSyntaxError{Position: token.position, Message: fmt.Sprintf("expected an integer between %d and %d but found %s", min, max, token.text)}
+	}
+	return value, token, nil
+}
+
+func (p *typeParser) expectString() (typeToken, error) {
+	token := p.next()
+	if token.kind != tokenString {
+		return token, p.unexpected(token, "a string literal")
+	}
+	return token, nil
+}
+
+func (p *typeParser) parseType() (*ChType, error) {
+	token := p.next()
+	if token.kind != tokenIdentifier {
+		return nil, p.unexpected(token, "a type name")
+	}
+	name := token.text
+	alias, isAlias := "", false
+	if _, ok := typeSpecs[name]; !ok {
+		alias, isAlias = typeAliases[strings.ToUpper(name)]
+		name = alias
+	}
+	spec, ok := typeSpecs[name]
+	if !ok {
+		return nil, &TypeSyntaxError{Position: token.position, Message: fmt.Sprintf("unknown type %q", token.text)}
+	}
+	chType := &ChType{Name: name, Position: token.position}
+
+	if isAlias && alias == "String" && p.accept("(") {
+		// The length of VARCHAR(N) like aliases is ignored by Clickhouse
+		if _, _, err := p.expectInteger(0, 1<<31-1); err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return chType, nil
+	}
+
+	if !p.accept("(") {
+		switch spec.kind {
+		case kindSimple, kindDateTime, kindDateTime64, kindFreeForm:
+			return chType, nil
+		default:
+			return nil, p.unexpected(p.peek(), fmt.Sprintf("\"(\" after %s", chType.Name))
+		}
+	}
+
+	var err error
+	switch spec.kind {
+	case kindSimple:
+		return nil, &TypeSyntaxError{Position: token.position, Message: fmt.Sprintf("type %s doesn't accept parameters", chType.Name)}
+	case kindFixedString:
+		err = p.parseLiteralParams(chType, func() (typeToken, error) {
+			_, t, err := p.expectInteger(1, 1<<31-1)
+			return t, err
+		})
+	case kindDecimal:
+		err = p.parseDecimalParams(chType)
+	case kindDecimalScale:
+		err = p.parseLiteralParams(chType, func() (typeToken, error) {
+			_, t, err := p.expectInteger(0, 76)
+			return t, err
+		})
+	case kindDateTime:
+		err = p.parseLiteralParams(chType, p.expectString)
+	case kindDateTime64:
+		err = p.parseDateTime64Params(chType)
+	case kindEnum:
+		err = p.parseEnumParams(chType)
+	case kindWrapper:
+		err = p.parseTypeParams(chType, 1, 1, false)
+	case kindMap:
+		err = p.parseTypeParams(chType, 2, 2, false)
+	case kindTuple:
+		err = p.parseTypeParams(chType, 1, -1, true)
+	case kindNested:
+		err = p.parseNestedParams(chType)
+	case kindVariant:
+		err = p.parseTypeParams(chType, 1, -1, false)
+	case kindAggregateFunction:
+		err = p.parseAggregateFunctionParams(chType)
+	case kindFreeForm:
+		err = p.parseFreeFormParams(chType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return chType, nil
+}
+
+func (p *typeParser) parseLiteralParams(chType *ChType, literal func() (typeToken, error)) error {
+	token, err := literal()
+	if err != nil {
+		return err
+	}
+	chType.Params = append(chType.Params, ChTypeParam{Literal: token.text})
+	return p.expect(")")
+}
+
+func (p *typeParser) parseDecimalParams(chType *ChType) error {
+	precision, precisionToken, err := p.expectInteger(1, 76)
+	if err != nil {
+		return err
+	}
+	chType.Params = append(chType.Params, ChTypeParam{Literal: precisionToken.text})
+	if p.accept(",") {
+		_, scaleToken, err := p.expectInteger(0, precision)
+		if err != nil {
+			return err
+		}
+		chType.Params = append(chType.Params, ChTypeParam{Literal: scaleToken.text})
+	}
+	return p.expect(")")
+}
+
+func (p *typeParser) parseDateTime64Params(chType *ChType) error {
+	_, precisionToken, err := p.expectInteger(0, 9)
+	if err != nil {
+		return err
+	}
+	chType.Params = append(chType.Params, ChTypeParam{Literal: precisionToken.text})
+	if p.accept(",") {
+		timezoneToken, err := p.expectString()
+		if err != nil {
+			return err
+		}
+		chType.Params = append(chType.Params, ChTypeParam{Literal: timezoneToken.text})
+	}
+	return p.expect(")")
+}
+
+func (p *typeParser) parseEnumParams(chType *ChType) error {
+	for {
+		nameToken, err := p.expectString()
+		if err != nil {
+			return err
+		}
+		param := ChTypeParam{Literal: nameToken.text}
+		if p.accept("=") {
+			valueToken := p.next()
+			if valueToken.kind != tokenNumber {
+				return p.unexpected(valueToken, "an enum value")
+			}
+			param.Value = valueToken.text
+		}
+		chType.Params = append(chType.Params, param)
+		if !p.accept(",") {
+			return p.expect(")")
+		}
+	}
+}
+
+// parseTypeParams parses a list of types, allowing element names (as in Tuple(a UInt8, b String)) if named is set.
+func (p *typeParser) parseTypeParams(chType *ChType, min int, max int, named bool) error {
+	for {
+		if max != -1 && len(chType.Params) == max {
+			return p.expect(")")
+		}
+		param := ChTypeParam{}
+		if named && p.peek().kind == tokenIdentifier && p.tokens[p.current+1].kind == tokenIdentifier {
+			param.Name = p.next().text
+		}
+		paramType, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		param.Type = paramType
+		chType.Params = append(chType.Params, param)
+
+		if !p.accept(",") {
+			if len(chType.Params) < min {
+				return p.unexpected(p.peek(), "\",\"")
+			}
+			return p.expect(")")
+		}
+	}
+}
+
+func (p *typeParser) parseNestedParams(chType *ChType) error {
+	for {
+		nameToken := p.next()
+		if nameToken.kind != tokenIdentifier {
+			return p.unexpected(nameToken, "a field name")
+		}
+		paramType, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		chType.Params = append(chType.Params, ChTypeParam{Name: nameToken.text, Type: paramType})
+		if !p.accept(",") {
+			return p.expect(")")
+		}
+	}
+}
+
+func (p *typeParser) parseAggregateFunctionParams(chType *ChType) error {
+	functionToken := p.next()
+	if functionToken.kind != tokenIdentifier {
+		return p.unexpected(functionToken, "an aggregate function name")
+	}
+	function := functionToken.text
+	if p.accept("(") {
+		var literals []string
+		for !p.accept(")") {
+			token := p.next()
+			if token.kind != tokenNumber && token.kind != tokenString {
+				return p.unexpected(token, "an aggregate function parameter")
+			}
+			literals = append(literals, token.text)
+			if !p.accept(",") {
+				if err := p.expect(")"); err != nil {
+					return err
+				}
+				break
+			}
+		}
+		function = fmt.Sprintf("%s(%s)", function, strings.Join(literals, ", "))
+	}
+	chType.Params = append(chType.Params, ChTypeParam{Literal: function})
+
+	// Aggregate functions without arguments, like count, don't need argument types
+	if chType.Name == "AggregateFunction" && p.accept(")") {
+		return nil
+	}
+	if err := p.expect(","); err != nil {
+		return err
+	}
+	return p.parseTypeParams(chType, 2, -1, false)
+}
+
+// parseFreeFormParams keeps the params of types like JSON as a single literal, only normalizing the whitespace.
+func (p *typeParser) parseFreeFormParams(chType *ChType) error {
+	var literal strings.Builder
+	var previous typeToken
+	depth := 1
+	for {
+		token := p.next()
+		switch {
+		case token.kind == tokenEOF:
+			return p.unexpected(token, "\")\"")
+		case token.kind == tokenPunctuation && token.text == "(":
+			depth++
+		case token.kind == tokenPunctuation && token.text == ")":
+			depth--
+		}
+		if depth == 0 {
+			chType.Params = append(chType.Params, ChTypeParam{Literal: literal.String()})
+			return nil
+		}
+		switch {
+		case token.kind == tokenPunctuation && token.text == "=":
+			literal.WriteString(" = ")
+		case token.kind == tokenPunctuation && token.text == ",":
+			literal.WriteString(", ")
+		case token.kind != tokenPunctuation && previous.kind != tokenPunctuation && literal.Len() > 0:
+			literal.WriteString(" " + token.text)
+		default:
+			literal.WriteString(token.text)
+		}
+		previous = token
+	}
+}
+
+func checkTypeNesting(chType *ChType, parent *ChType) error {
+	spec := typeSpecs[chType.Name]
+
+	if parent != nil {
+		switch parent.Name {
+		case "Nullable":
+			if !spec.nullable {
+				return &TypeSyntaxError{Position: chType.Position, Message: fmt.Sprintf("Nullable can't wrap %s", chType.Name)}
+			}
+		case "LowCardinality":
+			inner := chType
+			if chType.Name == "Nullable" {
+				inner = chType.Params[0].Type
+			}
+			if !typeSpecs[inner.Name].lowCardinality {
+				return &TypeSyntaxError{Position: chType.Position, Message: fmt.Sprintf("LowCardinality can't wrap %s", chType.Name)}
+			}
+		}
+		if chType.Name == "Nested" {
+			return &TypeSyntaxError{Position: chType.Position, Message: "Nested is only allowed as a column type"}
+		}
+	}
+
+	if chType.Name == "Map" {
+		key := chType.Params[0].Type
+		if !typeSpecs[key.Name].mapKey {
+			return &TypeSyntaxError{Position: key.Position, Message: fmt.Sprintf("%s can't be used as Map key", key.Name)}
+		}
+	}
+
+	for _, param := range chType.Params {
+		if param.Type == nil {
+			continue
+		}
+		if err := checkTypeNesting(param.Type, chType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CanonicalType returns the type expression the way Clickhouse reports it in system.columns: aliases are
+// resolved, DecimalN(S) is expanded to Decimal(P, S), Enum is sized to Enum8 or Enum16 with explicit values
+// and the whitespace is normalized. E.g. "Enum('a', 'b')" is reported as "Enum8('a' = 1, 'b' = 2)".
+func CanonicalType(input string) (string, error) {
+	chType, err := ParseType(input)
+	if err != nil {
+		return "", err
+	}
+	canonicalizeType(chType)
+	return chType.String(), nil
+}
+
+func canonicalizeType(chType *ChType) {
+	switch spec := typeSpecs[chType.Name]; {
+	case spec.kind == kindDecimalScale:
+		chType.Params = append([]ChTypeParam{{Literal: decimalPrecisions[chType.Name]}}, chType.Params...)
+		chType.Name = "Decimal"
+	case spec.kind == kindDecimal && len(chType.Params) == 1:
+		chType.Params = append(chType.Params, ChTypeParam{Literal: "0"})
+	case spec.kind == kindEnum:
+		canonicalizeEnum(chType)
+	}
+
+	for _, param := range chType.Params {
+		if param.Type != nil {
+			canonicalizeType(param.Type)
+		}
+	}
+}
+
+// canonicalizeEnum numbers the values left implicit, starting at 1 or following the previous value,
+// and picks Enum16 over Enum8 only when a value doesn't fit in Int8.
+func canonicalizeEnum(chType *ChType) {
+	next := 1
+	fitsInt8 := true
+	for i := range chType.Params {
+		value := next
+		if chType.Params[i].Value != "" {
+			value, _ = strconv.Atoi(chType.Params[i].Value)
+		}
+		chType.Params[i].Value = strconv.Itoa(value)
+		if value < -128 || value > 127 {
+			fitsInt8 = false
+		}
+		next = value + 1
+	}
+	if chType.Name == "Enum" {
+		chType.Name = "Enum8"
+		if !fitsInt8 {
+			chType.Name = "Enum16"
+		}
+	}
+}
+
+func (t *ChType) String() string {
+	if len(t.Params) == 0 {
+		return t.Name
+	}
+	params := make([]string, 0, len(t.Params))
+	for _, param := range t.Params {
+		switch {
+		case param.Type != nil && param.Name != "":
+			params = append(params, param.Name+" "+param.Type.String())
+		case param.Type != nil:
+			params = append(params, param.Type.String())
+		case param.Value != "":
+			params = append(params, param.Literal+" = "+param.Value)
+		default:
+			params = append(params, param.Literal)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", t.Name, strings.Join(params, ", "))
+}
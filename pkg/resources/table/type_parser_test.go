@@ -0,0 +1,137 @@
+package resourcetable
+
+import (
+	"testing"
+)
+
+func TestParseType_Valid(t *testing.T) {
+	validTypes := []string{
+		"UInt64",
+		"String",
+		"Nullable(String)",
+		"LowCardinality(String)",
+		"LowCardinality(Nullable(String))",
+		"Array(UInt64)",
+		"Array(Array(Nullable(Float64)))",
+		"Map(String, Float64)",
+		"Map(LowCardinality(String), Array(UInt8))",
+		"Decimal(18, 4)",
+		"Decimal(10)",
+		"Decimal64(4)",
+		"DateTime",
+		"DateTime('UTC')",
+		"DateTime64(3)",
+		"DateTime64(3, 'UTC')",
+		"Enum8('a' = 1, 'b' = -2)",
+		"Enum16('it''s' = 1)",
+		"Enum('a', 'b')",
+		"FixedString(16)",
+		"Tuple(UInt8, String)",
+		"Tuple(id UInt64, tags Array(String))",
+		"Nested(id UInt64, name String)",
+		"AggregateFunction(uniq, UInt64)",
+		"AggregateFunction(count)",
+		"INT",
+		"Nullable(varchar(255))",
+		"AggregateFunction(quantiles(0.5, 0.9), Float64)",
+		"SimpleAggregateFunction(sum, UInt64)",
+		"Variant(String, UInt64)",
+		"JSON",
+		"JSON(max_dynamic_paths = 16)",
+		"  Array( String )  ",
+	}
+
+	for _, validType := range validTypes {
+		if _, err := ParseType(validType); err != nil {
+			t.Errorf("ParseType(%q) returned unexpected error: %v", validType, err)
+		}
+	}
+}
+
+func TestParseType_Invalid(t *testing.T) {
+	invalidTypes := []struct {
+		value    string
+		position int
+	}{
+		{"Strin", 1},
+		{"", 1},
+		{"Nullable(Array(String))", 10},
+		{"Nullable(Nullable(String))", 10},
+		{"Nullable(LowCardinality(String))", 10},
+		{"LowCardinality(Array(String))", 16},
+		{"LowCardinality(UUID)", 16},
+		{"Map(Nullable(String), UInt8)", 5},
+		{"Map(Float64, UInt8)", 5},
+		{"Map(String)", 11},
+		{"Array(Nested(a UInt8))", 7},
+		{"Array(UInt64", 13},
+		{"Array(UInt64))", 14},
+		{"Array", 6},
+		{"Decimal(18, 20)", 13},
+		{"DateTime64(10)", 12},
+		{"DateTime64(3, UTC)", 15},
+		{"FixedString(0)", 13},
+		{"Enum8(a = 1)", 7},
+		{"UInt64(8)", 1},
+		{"Tuple(UInt8,)", 13},
+		{"String;", 7},
+		{"DateTime('UTC)", 10},
+	}
+
+	for _, invalidType := range invalidTypes {
+		_, err := ParseType(invalidType.value)
+		if err == nil {
+			t.Errorf("ParseType(%q) expected an error", invalidType.value)
+			continue
+		}
+		syntaxError, ok := err.(*TypeSyntaxError)
+		if !ok {
+			t.Errorf("ParseType(%q) returned %T, expected *TypeSyntaxError", invalidType.value, err)
+			continue
+		}
+		if syntaxError.Position != invalidType.position {
+			t.Errorf("ParseType(%q) reported position %d, expected %d: %v", invalidType.value, syntaxError.Position, invalidType.position, err)
+		}
+	}
+}
+
+func TestCanonicalType(t *testing.T) {
+	canonicalTypes := []struct {
+		value    string
+		expected string
+	}{
+		{"UInt64", "UInt64"},
+		{"  Array( Nullable(String) )  ", "Array(Nullable(String))"},
+		{"Map(String,Array(UInt8))", "Map(String, Array(UInt8))"},
+		{"INT", "Int32"},
+		{"bigint", "Int64"},
+		{"Nullable(VARCHAR(255))", "Nullable(String)"},
+		{"Array(DOUBLE)", "Array(Float64)"},
+		{"Decimal32(2)", "Decimal(9, 2)"},
+		{"Decimal64(4)", "Decimal(18, 4)"},
+		{"Decimal128(10)", "Decimal(38, 10)"},
+		{"Decimal256(20)", "Decimal(76, 20)"},
+		{"Decimal(10)", "Decimal(10, 0)"},
+		{"Decimal(18,4)", "Decimal(18, 4)"},
+		{"Enum('a'=1)", "Enum8('a' = 1)"},
+		{"Enum('a', 'b')", "Enum8('a' = 1, 'b' = 2)"},
+		{"Enum('a' = 1, 'b' = 1000)", "Enum16('a' = 1, 'b' = 1000)"},
+		{"Enum16('a' = 1)", "Enum16('a' = 1)"},
+		{"DateTime64(3,'UTC')", "DateTime64(3, 'UTC')"},
+		{"Tuple(id UInt64,tags Array(String))", "Tuple(id UInt64, tags Array(String))"},
+		{"AggregateFunction(count)", "AggregateFunction(count)"},
+		{"AggregateFunction(quantiles(0.5,0.9), Float64)", "AggregateFunction(quantiles(0.5, 0.9), Float64)"},
+		{"JSON(max_dynamic_paths=16)", "JSON(max_dynamic_paths = 16)"},
+	}
+
+	for _, canonicalType := range canonicalTypes {
+		actual, err := CanonicalType(canonicalType.value)
+		if err != nil {
+			t.Errorf("CanonicalType(%q) returned unexpected error: %v", canonicalType.value, err)
+			continue
+		}
+		if actual != canonicalType.expected {
+			t.Errorf("CanonicalType(%q) = %q, expected %q", canonicalType.value, actual, canonicalType.expected)
+		}
+	}
+}
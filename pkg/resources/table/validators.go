@@ -2,23 +2,25 @@ package resourcetable
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	v "github.com/go-playground/validator/v10"
 	hashicorpcty "github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+var functionNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 func ValidatePartitionBy(inValue any, p hashicorpcty.Path) diag.Diagnostics {
-	validate := v.New()
 	value := inValue.(string)
-	toAllowedPartitioningFunctions := "toYYYYMM toYYYYMMDD toYYYYMMDDhhmmss"
-	validation := fmt.Sprintf("oneof=%v", toAllowedPartitioningFunctions)
 	var diags diag.Diagnostics
-	if validate.Var(value, validation) != nil {
+	if value != "" && !functionNameRegex.MatchString(value) {
 		diag := diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "wrong value",
-			Detail:   fmt.Sprintf("%q is not %q", value, toAllowedPartitioningFunctions),
+			Detail:   fmt.Sprintf("%q is not a function name like toYYYYMM or toMonday", value),
 		}
 		diags = append(diags, diag)
 	}
@@ -26,38 +28,100 @@ func ValidatePartitionBy(inValue any, p hashicorpcty.Path) diag.Diagnostics {
 }
 
 func ValidateType(inValue any, p hashicorpcty.Path) diag.Diagnostics {
-	validate := v.New()
 	value := inValue.(string)
-	uintTypes := "UInt8 UInt16 UInt32 UInt64 UInt128 UInt256 Int8 Int16 Int32 Int64 Int128 Int256 Float32 Float64"
-	otherTypes := "Bool String UUID Date Date32 DateTime DateTime64 LowCardinality JSON"
-	validation := fmt.Sprintf("oneof=%v %v", uintTypes, otherTypes)
 	var diags diag.Diagnostics
-	if validate.Var(value, validation) != nil {
+	if _, err := CanonicalType(value); err != nil {
 		diag := diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "wrong value",
-			Detail:   fmt.Sprintf("%q is not %q %q", value, uintTypes, otherTypes),
+			Detail:   fmt.Sprintf("%q is not a valid Clickhouse type: %v", value, err),
 		}
 		diags = append(diags, diag)
 	}
 	return diags
 }
 
+// suppressTypeDiff ignores differences between equivalent type expressions, e.g. Decimal64(4) and Decimal(18, 4)
+// or INT and Int32, Clickhouse always reports the canonical form.
+func suppressTypeDiff(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	oldType, oldErr := CanonicalType(oldValue)
+	newType, newErr := CanonicalType(newValue)
+	if oldErr != nil || newErr != nil {
+		return oldValue == newValue
+	}
+	return oldType == newType
+}
+
 func ValidateOnClusterEngine(inValue any, p hashicorpcty.Path) diag.Diagnostics {
+	value := inValue.(string)
+	var diags diag.Diagnostics
+	if _, ok := EngineSpecs[value]; !ok {
+		diag := diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "wrong value",
+			Detail:   fmt.Sprintf("%q is not one of %s", value, strings.Join(SupportedEngines(), ", ")),
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+// suppressEngineParamQuotesDiff ignores quoting differences, engine_full reports identifiers
+// like database or table names of a Distributed table as string literals.
+func suppressEngineParamQuotesDiff(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	return strings.Trim(oldValue, "'") == strings.Trim(newValue, "'")
+}
+
+func ValidateDefaultKind(inValue any, p hashicorpcty.Path) diag.Diagnostics {
 	validate := v.New()
 	value := inValue.(string)
-	mergeTreeTypes := "ReplacingMergeTree"
-	replicatedTypes := "ReplicatedMergeTree"
-	distributedTypes := "Distributed"
-	validation := fmt.Sprintf("oneof=%v %v %v", replicatedTypes, distributedTypes, mergeTreeTypes)
+	defaultKinds := "DEFAULT MATERIALIZED ALIAS EPHEMERAL"
+	validation := fmt.Sprintf("oneof=%v", defaultKinds)
 	var diags diag.Diagnostics
 	if validate.Var(value, validation) != nil {
 		diag := diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "wrong value",
-			Detail:   fmt.Sprintf("%q is not %q %q %q", value, replicatedTypes, distributedTypes, mergeTreeTypes),
+			Detail:   fmt.Sprintf("%q is not %q", value, defaultKinds),
 		}
 		diags = append(diags, diag)
 	}
 	return diags
 }
+
+// suppressDefaultExpressionDiff ignores formatting differences between the configured expression and the one
+// reported by Clickhouse, as well as the implicit default of EPHEMERAL columns without expression.
+func suppressDefaultExpressionDiff(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	if newValue == "" {
+		defaultKind := d.Get(strings.TrimSuffix(k, "default_expression") + "default_kind").(string)
+		if defaultKind == "EPHEMERAL" {
+			return true
+		}
+	}
+	return normalizeExpression(oldValue) == normalizeExpression(newValue)
+}
+
+func suppressCodecDiff(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	return codecsEquivalent(oldValue, newValue)
+}
+
+func ValidateTTLAction(inValue any, p hashicorpcty.Path) diag.Diagnostics {
+	value := inValue.(string)
+	ttlActions := []string{"DELETE", "TO DISK", "TO VOLUME", "GROUP BY"}
+	var diags diag.Diagnostics
+	for _, action := range ttlActions {
+		if value == action {
+			return diags
+		}
+	}
+	diag := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "wrong value",
+		Detail:   fmt.Sprintf("%q is not one of %s", value, strings.Join(ttlActions, ", ")),
+	}
+	return append(diags, diag)
+}
+
+func suppressExpressionDiff(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	return normalizeExpression(oldValue) == normalizeExpression(newValue)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -15,6 +16,14 @@ func ResourceUser() *schema.Resource {
 		UpdateContext: resourceUserUpdate,
 		ReadContext:   resourceUserRead,
 		DeleteContext: resourceUserDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceUserImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Description: "User name",
@@ -22,7 +31,7 @@ func ResourceUser() *schema.Resource {
 				Required:    true,
 			},
 			"password": {
-				Description: "User password",
+				Description: "User password. ClickHouse doesn't expose it, so it's never read back: after an import the password is empty in state and it will be set to the configured value on the next apply",
 				Type:        schema.TypeString,
 				Required:    true,
 			},
@@ -50,6 +59,11 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("resource user read: %v", err))
 	}
+	if user == nil {
+		tflog.Warn(ctx, "User not found, removing it from state", map[string]interface{}{"name": userName})
+		d.SetId("")
+		return diags
+	}
 
 	if err := d.Set("name", user.Name); err != nil {
 		return diag.FromErr(err)
@@ -62,6 +76,18 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta any) dia
 	return diags
 }
 
+func resourceUserImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Users are imported by name, roles are rebuilt from system.users on read. The password can't be
+	// retrieved so it's left empty, and the next apply resets it to the configured one.
+	if err := d.Set("name", d.Id()); err != nil {
+		return nil, fmt.Errorf("resource user import: %v", err)
+	}
+	if err := d.Set("password", ""); err != nil {
+		return nil, fmt.Errorf("resource user import: %v", err)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
 
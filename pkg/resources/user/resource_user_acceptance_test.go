@@ -112,6 +112,28 @@ func TestAccResourceRole(t *testing.T) {
 		CheckDestroy: testAccCheckUserResourceDestroy([]string{userName1, userName2}),
 		Steps:        generateTestSteps(),
 	})
+	// Import, the password is never read back from ClickHouse
+	resource.Test(t, resource.TestCase{
+		Providers:    testutils.Provider(),
+		CheckDestroy: testAccCheckUserResourceDestroy([]string{userName1}),
+		Steps: append(generateTestSteps()[:1], resource.TestStep{
+			ResourceName:            userResource,
+			ImportState:             true,
+			ImportStateVerify:       true,
+			ImportStateVerifyIgnore: []string{"password"},
+		}),
+	})
+	// Users dropped outside terraform are recreated
+	resource.Test(t, resource.TestCase{
+		Providers:    testutils.Provider(),
+		CheckDestroy: testAccCheckUserResourceDestroy([]string{userName1}),
+		Steps: append(generateTestSteps()[:1], resource.TestStep{
+			PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("DROP USER %s", userName1)),
+			Config:             generateTestSteps()[0].Config,
+			PlanOnly:           true,
+			ExpectNonEmptyPlan: true,
+		}),
+	})
 }
 
 func testAccUserResource(userName string, password string, roles []string) string {
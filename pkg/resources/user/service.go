@@ -3,20 +3,19 @@ package resourceuser
 import (
 	"context"
 	"fmt"
-	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"strings"
 )
 
 type CHUserService struct {
-	CHConnection *driver.Conn
+	CHConnection common.Executor
 }
 
 func (us *CHUserService) GetUser(ctx context.Context, userName string) (*CHUser, error) {
-	roleQuery := fmt.Sprintf("SELECT name, default_roles_list FROM system.users WHERE name = '%s'", userName)
+	roleQuery := "SELECT name, default_roles_list FROM system.users WHERE name = ?"
 
-	rows, err := (*us.CHConnection).Query(ctx, roleQuery)
+	rows, err := us.CHConnection.Query(ctx, roleQuery, userName)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching user: %s", err)
 	}
@@ -39,15 +38,15 @@ func (us *CHUserService) CreateUser(ctx context.Context, userPlan UserResource)
 		rolesList = append(rolesList, role.(string))
 	}
 	query := fmt.Sprintf(
-		"CREATE USER %s IDENTIFIED WITH sha256_password BY '%s'",
-		userPlan.Name,
-		userPlan.Password,
+		"CREATE USER %s IDENTIFIED WITH sha256_password BY %s",
+		common.QuoteIdentifier(userPlan.Name),
+		common.QuoteString(userPlan.Password),
 	)
 
 	if len(rolesList) > 0 {
-		query = fmt.Sprintf("%s DEFAULT ROLE %s", query, strings.Join(rolesList, ","))
+		query = fmt.Sprintf("%s DEFAULT ROLE %s", query, strings.Join(common.QuoteIdentifiers(rolesList), ","))
 	}
-	err := (*us.CHConnection).Exec(ctx, query)
+	err := us.CHConnection.Exec(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error creating user: %s", err)
 	}
@@ -55,7 +54,7 @@ func (us *CHUserService) CreateUser(ctx context.Context, userPlan UserResource)
 }
 
 func (us *CHUserService) UpdateUser(ctx context.Context, userPlan UserResource, resourceData *schema.ResourceData) (*CHUser, error) {
-	conn := *us.CHConnection
+	conn := us.CHConnection
 	stateUserName, _ := resourceData.GetChange("name")
 	user, err := us.GetUser(ctx, stateUserName.(string))
 	if err != nil {
@@ -92,14 +91,14 @@ func (us *CHUserService) UpdateUser(ctx context.Context, userPlan UserResource,
 	}
 
 	if len(grantRoles) > 0 {
-		err := conn.Exec(ctx, fmt.Sprintf("GRANT %s TO %s", strings.Join(grantRoles, ","), stateUserName))
+		err := conn.Exec(ctx, fmt.Sprintf("GRANT %s TO %s", strings.Join(common.QuoteIdentifiers(grantRoles), ","), common.QuoteIdentifier(stateUserName.(string))))
 		if err != nil {
 			return nil, fmt.Errorf("error granting roles to user: %s", err)
 		}
 	}
 
 	if len(revokeRoles) > 0 {
-		err := conn.Exec(ctx, fmt.Sprintf("REVOKE %s FROM %s", strings.Join(revokeRoles, ","), stateUserName))
+		err := conn.Exec(ctx, fmt.Sprintf("REVOKE %s FROM %s", strings.Join(common.QuoteIdentifiers(revokeRoles), ","), common.QuoteIdentifier(stateUserName.(string))))
 		if err != nil {
 			return nil, fmt.Errorf("error revoking roles from user: %s", err)
 		}
@@ -109,20 +108,24 @@ func (us *CHUserService) UpdateUser(ctx context.Context, userPlan UserResource,
 	var changePasswordClause string
 
 	if userNameHasChange {
-		changeNameClause = fmt.Sprintf(" RENAME TO %s", userPlan.Name)
+		changeNameClause = fmt.Sprintf(" RENAME TO %s", common.QuoteIdentifier(userPlan.Name))
 	}
 
 	if userPasswordHasChange {
-		changePasswordClause = fmt.Sprintf(" IDENTIFIED with sha256_password BY '%s'", userPlan.Password)
+		changePasswordClause = fmt.Sprintf(" IDENTIFIED with sha256_password BY %s", common.QuoteString(userPlan.Password))
 	}
 
 	// After modify original role grants, we need to update default roles
+	defaultRoles := "NONE"
+	if roles := common.StringSetToList(userPlan.Roles); len(roles) > 0 {
+		defaultRoles = strings.Join(common.QuoteIdentifiers(roles), ",")
+	}
 	query := fmt.Sprintf(
 		"ALTER USER %s%s%s DEFAULT ROLE %s",
-		stateUserName,
+		common.QuoteIdentifier(stateUserName.(string)),
 		changeNameClause,
 		changePasswordClause,
-		strings.Join(common.StringSetToList(userPlan.Roles), ","),
+		defaultRoles,
 	)
 	err = conn.Exec(ctx, query)
 	if err != nil {
@@ -133,5 +136,5 @@ func (us *CHUserService) UpdateUser(ctx context.Context, userPlan UserResource,
 }
 
 func (us *CHUserService) DeleteUser(ctx context.Context, name string) error {
-	return (*us.CHConnection).Exec(ctx, fmt.Sprintf("DROP USER %s", name))
+	return us.CHConnection.Exec(ctx, fmt.Sprintf("DROP USER %s", common.QuoteIdentifier(name)))
 }
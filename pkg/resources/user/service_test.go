@@ -0,0 +1,94 @@
+package resourceuser
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestCreateUser(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.users", chfake.Row{"name": "bob", "default_roles_list": []string{"reader"}})
+	service := CHUserService{CHConnection: fake}
+
+	user, err := service.CreateUser(context.Background(), UserResource{
+		Name:     "bob",
+		Password: "it's secret",
+		Roles:    schema.NewSet(schema.HashString, []interface{}{"reader"}),
+	})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	expectedUser := CHUser{Name: "bob", Roles: []string{"reader"}}
+	if !reflect.DeepEqual(*user, expectedUser) {
+		t.Errorf("CreateUser() = %+v, expected %+v", *user, expectedUser)
+	}
+	expected := []string{"CREATE USER `bob` IDENTIFIED WITH sha256_password BY 'it\\'s secret' DEFAULT ROLE `reader`"}
+	if !reflect.DeepEqual(fake.Statements, expected) {
+		t.Errorf("unexpected statements %q, expected %q", fake.Statements, expected)
+	}
+}
+
+func TestGetAndDeleteUser(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.users")
+	service := CHUserService{CHConnection: fake}
+
+	user, err := service.GetUser(context.Background(), "bob")
+	if err != nil || user != nil {
+		t.Errorf("GetUser() of a missing user = %v, %v, expected nil", user, err)
+	}
+	if err := service.DeleteUser(context.Background(), "bob"); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	if expected := []string{"DROP USER `bob`"}; !reflect.DeepEqual(fake.Statements, expected) {
+		t.Errorf("unexpected statements %q, expected %q", fake.Statements, expected)
+	}
+}
+
+func TestUserStatements(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.users")
+	service := CHUserService{CHConnection: fake}
+	ctx := context.Background()
+
+	users := []UserResource{
+		{Name: "bob", Password: "secret", Roles: schema.NewSet(schema.HashString, []interface{}{})},
+		{Name: "alice", Password: "it's \\ secret", Roles: schema.NewSet(schema.HashString, []interface{}{"reader", "writer"})},
+	}
+	for _, user := range users {
+		if _, err := service.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser failed: %v", err)
+		}
+		if err := service.DeleteUser(ctx, user.Name); err != nil {
+			t.Fatalf("DeleteUser failed: %v", err)
+		}
+	}
+	golden.Assert(t, "user_statements", strings.Join(fake.Statements, "\n"))
+}
+
+func TestUpdateUserStatements(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.users", chfake.Row{"name": "bob", "default_roles_list": []string{}})
+	service := CHUserService{CHConnection: fake}
+
+	// An imported user without roles whose password is then reset
+	d := ResourceUser().Data(&terraform.InstanceState{ID: "bob", Attributes: map[string]string{"name": "bob", "roles.#": "0"}})
+	if err := d.Set("password", "secret"); err != nil {
+		t.Fatalf("setting the password failed: %v", err)
+	}
+	if _, err := service.UpdateUser(context.Background(), UserResource{
+		Name:     "bob",
+		Password: "secret",
+		Roles:    schema.NewSet(schema.HashString, []interface{}{}),
+	}, d); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	golden.Assert(t, "user_update_statements", strings.Join(fake.Statements, "\n"))
+}
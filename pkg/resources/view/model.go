@@ -0,0 +1,40 @@
+package resourceview
+
+import (
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+type CHView struct {
+	Database string `ch:"database"`
+	Name     string `ch:"name"`
+	Comment  string `ch:"comment"`
+	AsSelect string `ch:"as_select"`
+}
+
+type ViewResource struct {
+	Database string
+	Name     string
+	Cluster  string
+	Query    string
+	Comment  string
+}
+
+func (v *CHView) ToResource() *ViewResource {
+	comment, cluster := common.DecodeComment(v.Comment)
+
+	return &ViewResource{
+		Database: v.Database,
+		Name:     v.Name,
+		Cluster:  cluster,
+		Query:    v.AsSelect,
+		Comment:  comment,
+	}
+}
+
+// KeepStateQuery keeps the query written in the configuration when it's equivalent to the one stored by
+// Clickhouse, which reformats it, so only actual changes to the view are reported as drift.
+func (v *ViewResource) KeepStateQuery(stateQuery string) {
+	if common.QueriesEquivalent(stateQuery, v.Query, v.Database) {
+		v.Query = stateQuery
+	}
+}
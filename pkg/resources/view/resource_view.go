@@ -0,0 +1,186 @@
+package resourceview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceView() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource to manage views, including parameterized views",
+
+		CreateContext: resourceViewCreate,
+		ReadContext:   resourceViewRead,
+		UpdateContext: resourceViewUpdate,
+		DeleteContext: resourceViewDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceViewImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(common.DefaultTimeout),
+			Update: schema.DefaultTimeout(common.DefaultTimeout),
+			Delete: schema.DefaultTimeout(common.DefaultTimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"database": {
+				Description: "DB Name where the view belongs",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Description: "View name",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"cluster": {
+				Description: "Cluster name, the provider default cluster is used when not provided",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"query": {
+				Description: "SELECT query of the view. Parameterized views take their parameters as `{name:Type}` placeholders, e.g. `SELECT * FROM events WHERE user_id = {user_id:UInt64}`",
+				Type:        schema.TypeString,
+				Required:    true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return common.QueriesEquivalent(old, new, d.Get("database").(string))
+				},
+			},
+			"comment": {
+				Description: "View comment, it will be codified in a json along with some metadata information (like cluster name in case of clustering)",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+		},
+	}
+}
+
+func getViewResource(d *schema.ResourceData, defaultCluster string) ViewResource {
+	viewResource := ViewResource{
+		Database: d.Get("database").(string),
+		Name:     d.Get("name").(string),
+		Cluster:  d.Get("cluster").(string),
+		Query:    d.Get("query").(string),
+		Comment:  d.Get("comment").(string),
+	}
+	if viewResource.Cluster == "" {
+		viewResource.Cluster = defaultCluster
+	}
+	return viewResource
+}
+
+func resourceViewRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHViewService{CHConnection: client.ClickhouseConnection}
+
+	database := d.Get("database").(string)
+	viewName := d.Get("name").(string)
+
+	chView, err := chViewService.GetView(ctx, database, viewName)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("reading Clickhouse view: %v", err))
+	}
+	if chView == nil {
+		tflog.Warn(ctx, "View not found, removing it from state", map[string]interface{}{"database": database, "view": viewName})
+		d.SetId("")
+		return diags
+	}
+
+	viewResource := chView.ToResource()
+	viewResource.KeepStateQuery(d.Get("query").(string))
+	viewResource.Cluster = common.KnownCluster(viewResource.Cluster, d.Get("cluster").(string))
+
+	if err := d.Set("database", viewResource.Database); err != nil {
+		return diag.FromErr(fmt.Errorf("setting database: %v", err))
+	}
+	if err := d.Set("name", viewResource.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %v", err))
+	}
+	if err := d.Set("cluster", viewResource.Cluster); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster: %v", err))
+	}
+	if err := d.Set("query", viewResource.Query); err != nil {
+		return diag.FromErr(fmt.Errorf("setting query: %v", err))
+	}
+	if err := d.Set("comment", viewResource.Comment); err != nil {
+		return diag.FromErr(fmt.Errorf("setting comment: %v", err))
+	}
+
+	d.SetId(viewResource.Cluster + ":" + database + ":" + viewName)
+
+	return diags
+}
+
+func resourceViewImport(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	// Same ID format written by resourceViewCreate, the cluster part may be empty: <cluster>:<database>:<view>
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) < 3 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return nil, fmt.Errorf("unexpected import id %q, expected <cluster>:<database>:<view>", d.Id())
+	}
+
+	if err := d.Set("cluster", strings.Join(parts[:len(parts)-2], ":")); err != nil {
+		return nil, fmt.Errorf("setting cluster: %v", err)
+	}
+	if err := d.Set("database", parts[len(parts)-2]); err != nil {
+		return nil, fmt.Errorf("setting database: %v", err)
+	}
+	if err := d.Set("name", parts[len(parts)-1]); err != nil {
+		return nil, fmt.Errorf("setting name: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceViewCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHViewService{CHConnection: client.ClickhouseConnection}
+	viewResource := getViewResource(d, client.DefaultCluster)
+
+	if err := chViewService.CreateView(ctx, viewResource); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(viewResource.Cluster + ":" + viewResource.Database + ":" + viewResource.Name)
+
+	return diags
+}
+
+func resourceViewUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHViewService{CHConnection: client.ClickhouseConnection}
+
+	// Views have no ALTER statements for their query, the whole view is replaced atomically instead
+	if err := chViewService.ReplaceView(ctx, getViewResource(d, client.DefaultCluster)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceViewDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client := meta.(*common.ApiClient)
+	chViewService := CHViewService{CHConnection: client.ClickhouseConnection}
+
+	if err := chViewService.DeleteView(ctx, getViewResource(d, client.DefaultCluster)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
@@ -0,0 +1,106 @@
+package resourceview_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testResourceViewDatabaseName = "view_test_database"
+
+func TestAccResourceView(t *testing.T) {
+	const viewName = "daily_events"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: viewConfig(testResourceViewDatabaseName, viewName, "select toDate(eventTime) as day, count() as events\n\t\tfrom events\n\t\tgroup by day", "daily events"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("clickhouse_view.view", "name", viewName),
+					resource.TestCheckResourceAttr("clickhouse_view.view", "database", testResourceViewDatabaseName),
+					resource.TestCheckResourceAttr("clickhouse_view.view", "comment", "daily events"),
+				),
+			},
+			// REFORMATTED QUERY DOESN'T CHANGE THE VIEW
+			{
+				Config:   viewConfig(testResourceViewDatabaseName, viewName, "SELECT toDate(eventTime) AS day, count() AS events FROM events GROUP BY day", "daily events"),
+				PlanOnly: true,
+			},
+			// QUERY AND COMMENT ARE REPLACED IN PLACE
+			{
+				Config: viewConfig(testResourceViewDatabaseName, viewName, "SELECT toDate(eventTime) AS day, uniq(key) AS events FROM events GROUP BY day", "unique daily events"),
+				Check:  resource.TestCheckResourceAttr("clickhouse_view.view", "comment", "unique daily events"),
+			},
+			{
+				ResourceName:            "clickhouse_view.view",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"query"},
+			},
+			// QUERY CHANGED OUTSIDE TERRAFORM IS REPORTED
+			{
+				PreConfig:          testutils.ExecQuery(t, fmt.Sprintf("CREATE OR REPLACE VIEW %s.%s AS SELECT 1 AS day, 1 AS events", testResourceViewDatabaseName, viewName)),
+				Config:             viewConfig(testResourceViewDatabaseName, viewName, "SELECT toDate(eventTime) AS day, uniq(key) AS events FROM events GROUP BY day", "unique daily events"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceView_Parameterized(t *testing.T) {
+	const viewName = "key_events"
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:  func() { testutils.TestAccPreCheck(t) },
+		Providers: testutils.Provider(),
+		Steps: []resource.TestStep{
+			{
+				Config: viewConfig(testResourceViewDatabaseName, viewName, "SELECT * FROM events WHERE key = {key:Int64}", ""),
+				Check:  resource.TestCheckResourceAttr("clickhouse_view.view", "name", viewName),
+			},
+		},
+	})
+}
+
+func viewConfig(database string, viewName string, query string, comment string) string {
+	s := `
+	resource "clickhouse_db" "new_db_resource" {
+		name = "%_database_%"
+	}
+
+	resource "clickhouse_table" "events" {
+		database = clickhouse_db.new_db_resource.name
+		name = "events"
+		engine = "MergeTree"
+		order_by = ["key"]
+		column {
+			name = "key"
+			type = "Int64"
+		}
+		column {
+			name = "eventTime"
+			type = "DateTime"
+		}
+	}
+
+	resource "clickhouse_view" "view" {
+		database = clickhouse_db.new_db_resource.name
+		name = "%_viewName_%"
+		query = <<-EOT
+		%_query_%
+		EOT
+		comment = "%_comment_%"
+
+		depends_on = [clickhouse_table.events]
+	}`
+
+	s = strings.Replace(s, "%_database_%", database, -1)
+	s = strings.Replace(s, "%_viewName_%", viewName, -1)
+	s = strings.Replace(s, "%_query_%", query, -1)
+	s = strings.Replace(s, "%_comment_%", comment, -1)
+	return s
+}
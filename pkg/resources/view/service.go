@@ -0,0 +1,95 @@
+package resourceview
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+const viewEngine = "View"
+
+type CHViewService struct {
+	CHConnection common.Executor
+}
+
+func (vs *CHViewService) GetView(ctx context.Context, database string, name string) (*CHView, error) {
+	query := "SELECT database, name, comment, as_select FROM system.tables WHERE database = ? AND name = ? AND engine = ?"
+	row := vs.CHConnection.QueryRow(ctx, query, database, name, viewEngine)
+
+	if row.Err() != nil {
+		return nil, fmt.Errorf("reading view from Clickhouse: %v", row.Err())
+	}
+
+	var chView CHView
+	err := row.ScanStruct(&chView)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning Clickhouse view row: %v", err)
+	}
+	return &chView, nil
+}
+
+func (vs *CHViewService) GetDBViews(ctx context.Context, database string) ([]CHView, error) {
+	query := "SELECT database, name FROM system.tables WHERE database = ? AND engine = ?"
+	rows, err := vs.CHConnection.Query(ctx, query, database, viewEngine)
+
+	if err != nil {
+		return nil, fmt.Errorf("reading views from Clickhouse: %v", err)
+	}
+
+	var views []CHView
+	for rows.Next() {
+		var view CHView
+		err := rows.ScanStruct(&view)
+		if err != nil {
+			return nil, fmt.Errorf("scanning Clickhouse view row: %v", err)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+func (vs *CHViewService) CreateView(ctx context.Context, viewResource ViewResource) error {
+	err := vs.CHConnection.Exec(ctx, buildCreateViewQuery(viewResource, false))
+	if err != nil {
+		return fmt.Errorf("creating Clickhouse view: %v", err)
+	}
+	return nil
+}
+
+func (vs *CHViewService) ReplaceView(ctx context.Context, viewResource ViewResource) error {
+	err := vs.CHConnection.Exec(ctx, buildCreateViewQuery(viewResource, true))
+	if err != nil {
+		return fmt.Errorf("replacing Clickhouse view: %v", err)
+	}
+	return nil
+}
+
+func (vs *CHViewService) DeleteView(ctx context.Context, viewResource ViewResource) error {
+	query := fmt.Sprintf("DROP VIEW %s %s", common.QualifiedName(viewResource.Database, viewResource.Name), common.GetClusterStatement(viewResource.Cluster))
+	err := vs.CHConnection.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("deleting Clickhouse view: %v", err)
+	}
+	return nil
+}
+
+func buildCreateViewQuery(viewResource ViewResource, replace bool) string {
+	create := "CREATE VIEW"
+	if replace {
+		create = "CREATE OR REPLACE VIEW"
+	}
+	return fmt.Sprintf(
+		"%s %s %s AS %sCOMMENT %s",
+		create,
+		common.QualifiedName(viewResource.Database, viewResource.Name),
+		common.GetClusterStatement(viewResource.Cluster),
+		common.TrimQuery(viewResource.Query),
+		common.QuoteString(common.GetComment(viewResource.Comment, viewResource.Cluster)),
+	)
+}
@@ -0,0 +1,79 @@
+package resourceview
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/chfake"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/testutils/golden"
+)
+
+func TestGetView(t *testing.T) {
+	fake := chfake.New()
+	fake.AddRows("system.tables",
+		chfake.Row{"database": "db", "name": "events", "engine": "MergeTree"},
+		chfake.Row{
+			"database":  "db",
+			"name":      "daily_events",
+			"engine":    "View",
+			"comment":   `{"provider":"terraform-provider-clickhouse","version":1,"comment":"daily","cluster":"cluster"}`,
+			"as_select": "SELECT toDate(ts) AS day, count() FROM db.events GROUP BY day",
+		},
+	)
+	service := CHViewService{CHConnection: fake}
+
+	view, err := service.GetView(context.Background(), "db", "daily_events")
+	if err != nil {
+		t.Fatalf("GetView failed: %v", err)
+	}
+	viewResource := view.ToResource()
+	viewResource.KeepStateQuery("select toDate(ts) as day, count()\nfrom events\ngroup by day")
+	expected := ViewResource{
+		Database: "db",
+		Name:     "daily_events",
+		Cluster:  "cluster",
+		Query:    "select toDate(ts) as day, count()\nfrom events\ngroup by day",
+		Comment:  "daily",
+	}
+	if !reflect.DeepEqual(*viewResource, expected) {
+		t.Errorf("GetView() = %+v, expected %+v", *viewResource, expected)
+	}
+
+	viewResource = view.ToResource()
+	viewResource.KeepStateQuery("SELECT toDate(ts) AS day, count() FROM events GROUP BY ts")
+	if viewResource.Query != view.AsSelect {
+		t.Errorf("expected the changed query %q to be reported as drift, got %q", view.AsSelect, viewResource.Query)
+	}
+
+	for _, name := range []string{"events", "missing"} {
+		missing, err := service.GetView(context.Background(), "db", name)
+		if err != nil || missing != nil {
+			t.Errorf("GetView(%q) = %v, %v, expected nil", name, missing, err)
+		}
+	}
+}
+
+func TestViewStatements(t *testing.T) {
+	fake := chfake.New()
+	service := CHViewService{CHConnection: fake}
+	ctx := context.Background()
+
+	views := []ViewResource{
+		{Database: "db", Name: "daily_events", Query: "SELECT toDate(ts) AS day, count() FROM events GROUP BY day;", Comment: "daily"},
+		{Database: "db", Name: "user_events", Cluster: "cluster", Query: "SELECT * FROM events WHERE user_id = {user_id:UInt64} -- parameterized"},
+	}
+	for _, view := range views {
+		if err := service.CreateView(ctx, view); err != nil {
+			t.Fatalf("CreateView failed: %v", err)
+		}
+		if err := service.ReplaceView(ctx, view); err != nil {
+			t.Fatalf("ReplaceView failed: %v", err)
+		}
+		if err := service.DeleteView(ctx, view); err != nil {
+			t.Fatalf("DeleteView failed: %v", err)
+		}
+	}
+	golden.Assert(t, "view_statements", strings.Join(fake.Statements, "\n"))
+}
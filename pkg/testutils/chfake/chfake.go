@@ -0,0 +1,245 @@
+// Package chfake provides an in-memory Clickhouse executor for unit tests. It records every
+// statement it receives and answers simple queries on system tables from in-memory fixtures.
+package chfake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
+)
+
+// Row is a fixture row of a system table, its values keyed by column name.
+type Row map[string]any
+
+// Executor implements common.Executor without a server.
+type Executor struct {
+	// Tables holds the fixture rows of every known table, keyed by table name, e.g. "system.tables".
+	Tables map[string][]Row
+	// Statements are the statements sent through Exec, in order.
+	Statements []string
+	// Queries are the queries sent through Query and QueryRow, in order.
+	Queries []string
+	// Errors makes every statement or query containing the key fail with its error.
+	Errors map[string]error
+}
+
+var _ common.Executor = (*Executor)(nil)
+
+func New() *Executor {
+	return &Executor{
+		Tables: make(map[string][]Row),
+		Errors: make(map[string]error),
+	}
+}
+
+// AddRows adds fixture rows to a table, queries return them in the order they were added.
+func (e *Executor) AddRows(table string, rows ...Row) {
+	e.Tables[table] = append(e.Tables[table], rows...)
+}
+
+// FailOn makes every statement or query containing substring fail with err.
+func (e *Executor) FailOn(substring string, err error) {
+	e.Errors[substring] = err
+}
+
+func (e *Executor) failure(query string) error {
+	for substring, err := range e.Errors {
+		if strings.Contains(query, substring) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Executor) Exec(ctx context.Context, query string, args ...any) error {
+	e.Statements = append(e.Statements, query)
+	return e.failure(query)
+}
+
+func (e *Executor) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	e.Queries = append(e.Queries, query)
+	if err := e.failure(query); err != nil {
+		return nil, err
+	}
+	result, err := e.selectRows(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (e *Executor) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	e.Queries = append(e.Queries, query)
+	if err := e.failure(query); err != nil {
+		return &row{err: err}
+	}
+	result, err := e.selectRows(query, args)
+	return &row{rows: result, err: err}
+}
+
+// selectRegex matches the queries supported by the fake: a list of columns from a single table,
+// optionally filtered by equality conditions on bound parameters. Columns selected through an
+// expression, e.g. toString(uuid) AS uuid, are read from the fixture value of their alias.
+var selectRegex = regexp.MustCompile(`(?is)^\s*SELECT\s+(.+?)\s+FROM\s+(\S+)(?:\s+WHERE\s+(.+?))?(?:\s+ORDER\s+BY\s+.+)?\s*$`)
+var conditionRegex = regexp.MustCompile(`(\w+)\s*=\s*\?`)
+var aliasRegex = regexp.MustCompile(`(?i)\sAS\s+(\w+)$`)
+
+func (e *Executor) selectRows(query string, args []any) (*rows, error) {
+	matches := selectRegex.FindStringSubmatch(query)
+	if matches == nil {
+		return nil, fmt.Errorf("chfake: unsupported query %q", query)
+	}
+	fixtures, ok := e.Tables[matches[2]]
+	if !ok {
+		return nil, fmt.Errorf("chfake: unknown table %s", matches[2])
+	}
+
+	var columns []string
+	for _, column := range strings.Split(matches[1], ",") {
+		column = strings.TrimSpace(column)
+		if alias := aliasRegex.FindStringSubmatch(column); alias != nil {
+			column = alias[1]
+		}
+		columns = append(columns, column)
+	}
+	conditions := conditionRegex.FindAllStringSubmatch(matches[3], -1)
+	if len(conditions) != len(args) {
+		return nil, fmt.Errorf("chfake: %d conditions but %d arguments in query %q", len(conditions), len(args), query)
+	}
+
+	result := &rows{columns: columns}
+	for _, fixture := range fixtures {
+		matching := true
+		for i, condition := range conditions {
+			if !reflect.DeepEqual(fixture[condition[1]], args[i]) {
+				matching = false
+			}
+		}
+		if !matching {
+			continue
+		}
+		var values []any
+		for _, column := range columns {
+			values = append(values, fixture[column])
+		}
+		result.values = append(result.values, values)
+	}
+	return result, nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]any
+	current int
+}
+
+func (r *rows) Next() bool {
+	if r.current >= len(r.values) {
+		return false
+	}
+	r.current++
+	return true
+}
+
+func (r *rows) Scan(dest ...any) error {
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf("chfake: %d destinations for %d columns", len(dest), len(r.columns))
+	}
+	for i, value := range r.values[r.current-1] {
+		if err := assign(dest[i], value); err != nil {
+			return fmt.Errorf("chfake: column %s: %v", r.columns[i], err)
+		}
+	}
+	return nil
+}
+
+// ScanStruct sets the fields of dest from the columns named by their ch tag, like the driver does.
+func (r *rows) ScanStruct(dest any) error {
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("chfake: ScanStruct expects a pointer to a struct, got %T", dest)
+	}
+	target = target.Elem()
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		name := field.Tag.Get("ch")
+		if name == "" {
+			name = field.Name
+		}
+		for j, column := range r.columns {
+			if column != name {
+				continue
+			}
+			if err := assign(target.Field(i).Addr().Interface(), r.values[r.current-1][j]); err != nil {
+				return fmt.Errorf("chfake: column %s: %v", column, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *rows) ColumnTypes() []driver.ColumnType { return nil }
+func (r *rows) Totals(dest ...any) error         { return nil }
+func (r *rows) Columns() []string                { return r.columns }
+func (r *rows) Close() error                     { return nil }
+func (r *rows) Err() error                       { return nil }
+
+type row struct {
+	rows *rows
+	err  error
+}
+
+func (r *row) Err() error {
+	return r.err
+}
+
+func (r *row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if !r.rows.Next() {
+		return sql.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
+
+func (r *row) ScanStruct(dest any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if !r.rows.Next() {
+		return sql.ErrNoRows
+	}
+	return r.rows.ScanStruct(dest)
+}
+
+// assign sets the value pointed by dest, missing fixture values are left as zero values.
+func assign(dest any, value any) error {
+	if value == nil {
+		return nil
+	}
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer {
+		return fmt.Errorf("destination %T is not a pointer", dest)
+	}
+	source := reflect.ValueOf(value)
+	switch {
+	case source.Type().AssignableTo(target.Elem().Type()):
+		target.Elem().Set(source)
+	case isNumber(source.Kind()) && isNumber(target.Elem().Kind()):
+		target.Elem().Set(source.Convert(target.Elem().Type()))
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, dest)
+	}
+	return nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
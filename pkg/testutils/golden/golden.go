@@ -0,0 +1,38 @@
+// Package golden compares generated SQL with the golden files kept under the testdata directory of
+// the package being tested. Run the tests with -update to rewrite the golden files after an
+// intended change, and review their diff.
+package golden
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the generated SQL")
+
+// Assert compares actual with the content of testdata/<name>.golden.
+func Assert(t *testing.T, name string, actual string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	actual += "\n"
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file, run the tests with -update to create it: %v", err)
+	}
+	if string(expected) != actual {
+		t.Errorf("generated SQL doesn't match %s, run the tests with -update if the change is intended\ngot:\n%s\nexpected:\n%s", path, actual, expected)
+	}
+}
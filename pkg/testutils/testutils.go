@@ -1,12 +1,14 @@
 package testutils
 
 import (
+	"context"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"strconv"
 	"testing"
 
+	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/common"
 	"github.com/IvanOfThings/terraform-provider-clickhouse/pkg/provider"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -81,3 +83,14 @@ func CheckStateSetAttr(attrKey string, resource string, expectedItems []string)
 		return nil
 	}
 }
+
+// ExecQuery returns a PreConfig function running a query with the provider connection, it's meant to
+// change objects outside terraform so drift detection can be checked in the following step.
+func ExecQuery(t *testing.T, query string) func() {
+	return func() {
+		client := TestAccProvider.Meta().(*common.ApiClient)
+		if err := client.ClickhouseConnection.Exec(context.Background(), query); err != nil {
+			t.Fatalf("executing %q: %v", query, err)
+		}
+	}
+}